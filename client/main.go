@@ -1,7 +1,9 @@
 // This file implements a client for interacting with a Tendermint gRPC server.
 //
-// It supports various commands, such as GetNodeInfo, GetSyncing, GetLatestBlock, GetBlockByHeight, GetLatestValidatorSet,
-// GetValidatorSetByHeight, GetABCIInfo, and GetStatusInfo.
+// It supports various commands, such as GetNodeInfo, GetSyncing, GetLatestBlock, GetBlockByHeight, GetBlockTxs, GetTx,
+// GetTxsByHeight, SearchTxs, GetBlockResults, GetLatestValidatorSet,
+// GetValidatorSetByHeight, DiffValidatorSets, GetValidatorSetStats, GetValidatorUptime, GetMissedBlocks, GetProposers,
+// VerifyCommit, GetABCIInfo, and GetStatusInfo.
 //
 // When executed, the CLI parses the command-line arguments to determine which command to run and calls the corresponding
 // gRPC method on the Tendermint server. It then prints the response to standard output in JSON format. If an error occurs,
@@ -14,23 +16,31 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	_ "grpc_server4/grpczstd"
 	types "grpc_server4/proto/generated"
 	"log"
+	"net"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/osmosis-labs/osmosis/v12/app"
 	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
 )
 
 // The GRPC_SERVER_ADDRESS constant represents the address of the gRPC server to be used by the client.
 
 const (
-	GRPC_SERVER_ADDRESS = "localhost:9090"
-	CHAIN_ID            = "osmosis-1"
+	GRPC_SERVER_ADDRESS  = "localhost:9090"
+	ADMIN_SERVER_ADDRESS = "localhost:9091"
+	CHAIN_ID             = "osmosis-1"
+	// MAX_MSG_SIZE bounds the responses, the blocks with many txs exceed the 4 MiB gRPC default
+	MAX_MSG_SIZE = 64 << 20
 )
 
 // The Ccontext variable is of type client.Context and is used for client initialization.
@@ -53,24 +63,89 @@ func InitCcontext() {
 	conf.SetBech32PrefixForAccount("osmo", "osmopub")
 }
 
+// callContext returns the context of a call, selecting the server chain with the x-chain-id header when chainID
+// is set, and pinning the staking state with the x-cosmos-block-height header when stateHeight is set
+func callContext(chainID string, stateHeight int64) context.Context {
+	ctx := context.Background()
+	if chainID != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-chain-id", chainID)
+	}
+	if stateHeight > 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-cosmos-block-height", strconv.FormatInt(stateHeight, 10))
+	}
+	return ctx
+}
+
+// unixDialer dials the unix socket of an address starting with unix://, and TCP otherwise
+func unixDialer() grpc.DialOption {
+	return grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+		if strings.HasPrefix(address, "unix://") {
+			return (&net.Dialer{}).DialContext(ctx, "unix", strings.TrimPrefix(address, "unix://"))
+		}
+		return (&net.Dialer{}).DialContext(ctx, "tcp", address)
+	})
+}
+
+// adminCall dials the AdminService at address, makes the call and prints its response as JSON
+func adminCall(address, name string, call func(ctx context.Context, c types.AdminServiceClient) (interface{}, error)) {
+	conn, err := grpc.Dial(
+		address,             // your admin server address.
+		grpc.WithInsecure(), // The admin listener doesn't support any transport security mechanism.
+		unixDialer(),
+	)
+	if err != nil {
+		log.Fatalf("dial err: %v", err)
+	}
+	defer conn.Close()
+	r, err := call(context.Background(), types.NewAdminServiceClient(conn))
+	if err != nil {
+		log.Fatalf("%s err: %v", name, err)
+	}
+	out, err := json.Marshal(r)
+	if err != nil {
+		log.Fatalf("%s err: %v", name, err)
+	}
+	fmt.Println(string(out))
+}
+
 func main() {
 	// Initialize the global Ccontext object
 	InitCcontext()
 
 	// Parse command line arguments
+	decodeTxs := flag.Bool("decode", false, "decode block transactions with the Osmosis codec")
+	quorum := flag.Bool("quorum", false, "read GetBlockByHeight and GetValidatorSetByHeight from every quorum upstream of the server chain and require them to agree")
+	verify := flag.Bool("verify", false, "recompute and check the hashes of the block returned by GetBlockByHeight, and the commit signatures read by GetValidatorUptime and GetMissedBlocks")
+	page := flag.Int("page", 1, "page number for SearchTxs")
+	limit := flag.Int("limit", 30, "page size for SearchTxs")
+	desc := flag.Bool("desc", false, "order SearchTxs results by descending height")
+	all := flag.Bool("all", true, "fetch the complete validator set instead of its first page")
+	topN := flag.Int("top", 10, "number of largest validators summed by GetValidatorSetStats")
+	window := flag.Int64("window", 100, "number of latest heights reported by GetMissedBlocks")
+	stateHeight := flag.Int64("state-height", 0, "height of the staking state joined to the validators, the latest when zero")
+	chainID := flag.String("chain", "", "chain id of the server chain registry serving the command, the server default chain when empty")
+	addr := flag.String("addr", GRPC_SERVER_ADDRESS, "address of the server, host:port or unix:///path of a unix socket")
+	admin := flag.String("admin", ADMIN_SERVER_ADDRESS, "address of the server AdminService, host:port or unix:///path of a unix socket")
+	compress := flag.String("compress", "", "compression of the calls and their responses, gzip or zstd, none when empty")
 	flag.Parse()
 	args := flag.Args()
 
 	// Ensure that the command is specified in the arguments
 	if len(args) == 0 {
-		fmt.Println("command should be oneof: GetNodeInfo,GetSyncing,GetLatestBlock,GetBlockByHeight,GetLatestValidatorSet,GetValidatorSetByHeight, GetABCIInfo, GetStatusInfo")
+		fmt.Println("command should be oneof: GetNodeInfo,GetSyncing,GetLatestBlock,GetBlockByHeight,GetBlockTxs,GetTx,GetTxsByHeight,SearchTxs,GetBlockResults,GetLatestValidatorSet,GetValidatorSetByHeight,DiffValidatorSets,GetValidatorSetStats,GetValidatorUptime,GetMissedBlocks,GetProposers,VerifyCommit, GetABCIInfo, GetStatusInfo, ListUpstreams, ListCaches, FlushCaches, GetConfig, SetLogLevel, ReconnectUpstreams")
 		return
 	}
 
 	// Establish a gRPC connection to the server
+	callOptions := []grpc.CallOption{grpc.MaxCallRecvMsgSize(MAX_MSG_SIZE)}
+	if *compress != "" {
+		callOptions = append(callOptions, grpc.UseCompressor(*compress))
+	}
 	grpcConn, err := grpc.Dial(
-		GRPC_SERVER_ADDRESS, // your gRPC server address.
+		*addr,               // your gRPC server address.
 		grpc.WithInsecure(), // The SDK doesn't support any transport security mechanism.
+		grpc.WithDefaultCallOptions(callOptions...),
+		unixDialer(),
 	)
 	if err != nil {
 		log.Fatalf("dial err: %v", err)
@@ -89,7 +164,7 @@ func main() {
 	switch args[0] {
 	case "GetNodeInfo":
 		// Call the GetNodeInfo RPC method and print the response
-		ctx := context.Background()
+		ctx := callContext(*chainID, *stateHeight)
 		r, err := c.GetNodeInfo(ctx, &types.GetNodeInfoRequest{})
 		if err != nil {
 			log.Fatalf("GetNodeInfo err: %v", err)
@@ -104,7 +179,7 @@ func main() {
 
 	case "GetSyncing":
 		// Call the GetSyncing RPC method and print the response
-		ctx := context.Background()
+		ctx := callContext(*chainID, *stateHeight)
 		r, err := c.GetSyncing(ctx, &types.GetSyncingRequest{})
 		if err != nil {
 			log.Fatalf("GetSyncing err: %v", err)
@@ -114,8 +189,8 @@ func main() {
 
 	case "GetLatestBlock":
 		// Call the GetLatestBlock RPC method and print the response
-		ctx := context.Background()
-		r, err := c.GetLatestBlock(ctx, &types.GetLatestBlockRequest{})
+		ctx := callContext(*chainID, *stateHeight)
+		r, err := c.GetLatestBlock(ctx, &types.GetLatestBlockRequest{DecodeTxs: *decodeTxs})
 		if err != nil {
 			log.Fatalf("GetLatestBlock err: %v", err)
 		}
@@ -128,7 +203,7 @@ func main() {
 
 	case "GetBlockByHeight":
 		// Call the GetBlockByHeight RPC method with the specified block height and print the response
-		ctx := context.Background()
+		ctx := callContext(*chainID, *stateHeight)
 		if len(args) < 2 {
 			fmt.Println("this command need the height!!!")
 			return
@@ -138,7 +213,7 @@ func main() {
 			log.Fatalf("GetSyncing err: %v", err)
 		}
 
-		r, err := c.GetBlockByHeight(ctx, &types.GetBlockByHeightRequest{Height: height})
+		r, err := c.GetBlockByHeight(ctx, &types.GetBlockByHeightRequest{Height: height, DecodeTxs: *decodeTxs, Verify: *verify, Quorum: *quorum})
 		if err != nil {
 			log.Fatalf("GetBlockByHeight err: %v", err)
 			return
@@ -150,10 +225,116 @@ func main() {
 		}
 		// Print the response
 		fmt.Println(string(out))
+	case "GetBlockTxs":
+		// Call the GetBlockTxs RPC method with the specified block height and print the decoded transactions
+		ctx := callContext(*chainID, *stateHeight)
+		if len(args) < 2 {
+			fmt.Println("this command need the height!!!")
+			return
+		}
+		height, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			log.Fatalf("GetBlockTxs err: %v", err)
+		}
+		r, err := c.GetBlockTxs(ctx, &types.GetBlockTxsRequest{Height: height})
+		if err != nil {
+			log.Fatalf("GetBlockTxs err: %v", err)
+		}
+		out, err := json.Marshal(r)
+		if err != nil {
+			log.Fatalf("GetBlockTxs err: %v", err)
+			return
+		}
+		fmt.Println(string(out))
+	case "GetTx":
+		// Call the GetTx RPC method with the specified tx hash and print the response
+		ctx := callContext(*chainID, *stateHeight)
+		if len(args) < 2 {
+			fmt.Println("this command need the tx hash!!!")
+			return
+		}
+		r, err := c.GetTx(ctx, &types.GetTxRequest{Hash: args[1]})
+		if err != nil {
+			log.Fatalf("GetTx err: %v", err)
+		}
+		out, err := json.Marshal(r)
+		if err != nil {
+			log.Fatalf("GetTx err: %v", err)
+			return
+		}
+		fmt.Println(string(out))
+	case "GetTxsByHeight":
+		// Call the GetTxsByHeight RPC method with the specified block height and print the response
+		ctx := callContext(*chainID, *stateHeight)
+		if len(args) < 2 {
+			fmt.Println("this command need the height!!!")
+			return
+		}
+		height, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			log.Fatalf("GetTxsByHeight err: %v", err)
+		}
+		r, err := c.GetTxsByHeight(ctx, &types.GetTxsByHeightRequest{Height: height})
+		if err != nil {
+			log.Fatalf("GetTxsByHeight err: %v", err)
+		}
+		out, err := json.Marshal(r)
+		if err != nil {
+			log.Fatalf("GetTxsByHeight err: %v", err)
+			return
+		}
+		fmt.Println(string(out))
+	case "SearchTxs":
+		// Call the SearchTxs RPC method with the specified event query and print the response
+		ctx := callContext(*chainID, *stateHeight)
+		if len(args) < 2 {
+			fmt.Println("this command need the event query!!!")
+			return
+		}
+		orderBy := types.OrderBy_ORDER_BY_ASC
+		if *desc {
+			orderBy = types.OrderBy_ORDER_BY_DESC
+		}
+		r, err := c.SearchTxs(ctx, &types.SearchTxsRequest{
+			Query:   args[1],
+			Page:    int32(*page),
+			Limit:   int32(*limit),
+			OrderBy: orderBy,
+		})
+		if err != nil {
+			log.Fatalf("SearchTxs err: %v", err)
+		}
+		out, err := json.Marshal(r)
+		if err != nil {
+			log.Fatalf("SearchTxs err: %v", err)
+			return
+		}
+		fmt.Println(string(out))
+	case "GetBlockResults":
+		// Call the GetBlockResults RPC method with the specified block height and print the response
+		ctx := callContext(*chainID, *stateHeight)
+		if len(args) < 2 {
+			fmt.Println("this command need the height!!!")
+			return
+		}
+		height, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			log.Fatalf("GetBlockResults err: %v", err)
+		}
+		r, err := c.GetBlockResults(ctx, &types.GetBlockResultsRequest{Height: height})
+		if err != nil {
+			log.Fatalf("GetBlockResults err: %v", err)
+		}
+		out, err := json.Marshal(r)
+		if err != nil {
+			log.Fatalf("GetBlockResults err: %v", err)
+			return
+		}
+		fmt.Println(string(out))
 	case "GetLatestValidatorSet":
 		// Call the GetLatestValidatorSet RPC method and print the response
-		ctx := context.Background()
-		r, err := c.GetLatestValidatorSet(ctx, &types.GetLatestValidatorSetRequest{})
+		ctx := callContext(*chainID, *stateHeight)
+		r, err := c.GetLatestValidatorSet(ctx, &types.GetLatestValidatorSetRequest{All: *all})
 		if err != nil {
 			log.Fatalf("GetLatestValidatorSet err: %v", err)
 			return
@@ -166,7 +347,7 @@ func main() {
 		fmt.Println(string(out))
 	case "GetValidatorSetByHeight":
 		// Call the GetValidatorSetByHeight RPC method with the specified block height and print the response
-		ctx := context.Background()
+		ctx := callContext(*chainID, *stateHeight)
 		if len(args) < 2 {
 			fmt.Println("this command need the height!!!")
 			return
@@ -175,7 +356,7 @@ func main() {
 		if err != nil {
 			log.Fatalf("GetSyncing err: %v", err)
 		}
-		r, err := c.GetValidatorSetByHeight(ctx, &types.GetValidatorSetByHeightRequest{Height: height})
+		r, err := c.GetValidatorSetByHeight(ctx, &types.GetValidatorSetByHeightRequest{Height: height, All: *all, Quorum: *quorum})
 		if err != nil {
 			log.Fatalf("GetValidatorSetByHeight err: %v", err)
 		}
@@ -186,10 +367,156 @@ func main() {
 		}
 		fmt.Println(string(out))
 
+	case "DiffValidatorSets":
+		// Call the DiffValidatorSets RPC method with the specified from and to heights and print the response
+		ctx := callContext(*chainID, *stateHeight)
+		if len(args) < 3 {
+			fmt.Println("this command need the from and to heights!!!")
+			return
+		}
+		fromHeight, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			log.Fatalf("DiffValidatorSets err: %v", err)
+		}
+		toHeight, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			log.Fatalf("DiffValidatorSets err: %v", err)
+		}
+		r, err := c.DiffValidatorSets(ctx, &types.DiffValidatorSetsRequest{FromHeight: fromHeight, ToHeight: toHeight})
+		if err != nil {
+			log.Fatalf("DiffValidatorSets err: %v", err)
+		}
+		out, err := json.Marshal(r)
+		if err != nil {
+			log.Fatalf("DiffValidatorSets err: %v", err)
+			return
+		}
+		fmt.Println(string(out))
+
+	case "GetValidatorSetStats":
+		// Call the GetValidatorSetStats RPC method with the optional block height and print the response
+		ctx := callContext(*chainID, *stateHeight)
+		var height int64
+		if len(args) > 1 {
+			height, err = strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				log.Fatalf("GetValidatorSetStats err: %v", err)
+			}
+		}
+		r, err := c.GetValidatorSetStats(ctx, &types.GetValidatorSetStatsRequest{Height: height, TopN: int32(*topN)})
+		if err != nil {
+			log.Fatalf("GetValidatorSetStats err: %v", err)
+		}
+		out, err := json.Marshal(r)
+		if err != nil {
+			log.Fatalf("GetValidatorSetStats err: %v", err)
+			return
+		}
+		fmt.Println(string(out))
+
+	case "GetValidatorUptime":
+		// Call the GetValidatorUptime RPC method with the specified validator and height range and print the response
+		ctx := callContext(*chainID, *stateHeight)
+		if len(args) < 4 {
+			fmt.Println("this command need the validator and the from and to heights!!!")
+			return
+		}
+		fromHeight, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			log.Fatalf("GetValidatorUptime err: %v", err)
+		}
+		toHeight, err := strconv.ParseInt(args[3], 10, 64)
+		if err != nil {
+			log.Fatalf("GetValidatorUptime err: %v", err)
+		}
+		r, err := c.GetValidatorUptime(ctx, &types.GetValidatorUptimeRequest{Validator: args[1], FromHeight: fromHeight, ToHeight: toHeight, VerifyCommits: *verify})
+		if err != nil {
+			log.Fatalf("GetValidatorUptime err: %v", err)
+		}
+		out, err := json.Marshal(r)
+		if err != nil {
+			log.Fatalf("GetValidatorUptime err: %v", err)
+			return
+		}
+		fmt.Println(string(out))
+
+	case "GetMissedBlocks":
+		// Call the GetMissedBlocks RPC method with the optional height range, or the -window latest heights, and print the response
+		ctx := callContext(*chainID, *stateHeight)
+		req := &types.GetMissedBlocksRequest{Window: *window, VerifyCommits: *verify}
+		if len(args) > 2 {
+			req.FromHeight, err = strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				log.Fatalf("GetMissedBlocks err: %v", err)
+			}
+			req.ToHeight, err = strconv.ParseInt(args[2], 10, 64)
+			if err != nil {
+				log.Fatalf("GetMissedBlocks err: %v", err)
+			}
+		}
+		r, err := c.GetMissedBlocks(ctx, req)
+		if err != nil {
+			log.Fatalf("GetMissedBlocks err: %v", err)
+		}
+		out, err := json.Marshal(r)
+		if err != nil {
+			log.Fatalf("GetMissedBlocks err: %v", err)
+			return
+		}
+		fmt.Println(string(out))
+
+	case "GetProposers":
+		// Call the GetProposers RPC method with the specified height range and print the response
+		ctx := callContext(*chainID, *stateHeight)
+		if len(args) < 3 {
+			fmt.Println("this command need the from and to heights!!!")
+			return
+		}
+		fromHeight, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			log.Fatalf("GetProposers err: %v", err)
+		}
+		toHeight, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			log.Fatalf("GetProposers err: %v", err)
+		}
+		r, err := c.GetProposers(ctx, &types.GetProposersRequest{FromHeight: fromHeight, ToHeight: toHeight})
+		if err != nil {
+			log.Fatalf("GetProposers err: %v", err)
+		}
+		out, err := json.Marshal(r)
+		if err != nil {
+			log.Fatalf("GetProposers err: %v", err)
+			return
+		}
+		fmt.Println(string(out))
+
+	case "VerifyCommit":
+		// Call the VerifyCommit RPC method with the specified block height and print the response
+		ctx := callContext(*chainID, *stateHeight)
+		if len(args) < 2 {
+			fmt.Println("this command need the height!!!")
+			return
+		}
+		height, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			log.Fatalf("VerifyCommit err: %v", err)
+		}
+		r, err := c.VerifyCommit(ctx, &types.VerifyCommitRequest{Height: height})
+		if err != nil {
+			log.Fatalf("VerifyCommit err: %v", err)
+		}
+		out, err := json.Marshal(r)
+		if err != nil {
+			log.Fatalf("VerifyCommit err: %v", err)
+			return
+		}
+		fmt.Println(string(out))
+
 	case "GetABCIInfo":
 		// Call the GetABCIInfo RPC method and print the response
-		ctx := context.Background()
-		conn, err := grpc.Dial(GRPC_SERVER_ADDRESS, grpc.WithInsecure())
+		ctx := callContext(*chainID, *stateHeight)
+		conn, err := grpc.Dial(*addr, grpc.WithInsecure(), unixDialer())
 		if err != nil {
 			log.Fatalf("dial err: %v", err)
 		}
@@ -207,8 +534,8 @@ func main() {
 
 	case "GetStatusInfo":
 		// Call the GetStatusInfo RPC method and print the response
-		ctx := context.Background()
-		conn, err := grpc.Dial(GRPC_SERVER_ADDRESS, grpc.WithInsecure())
+		ctx := callContext(*chainID, *stateHeight)
+		conn, err := grpc.Dial(*addr, grpc.WithInsecure(), unixDialer())
 		if err != nil {
 			log.Fatalf("dial err: %v", err)
 		}
@@ -223,8 +550,55 @@ func main() {
 			return
 		}
 		fmt.Println(string(out))
+
+	case "ListUpstreams":
+		// Call the ListUpstreams admin method, for the -chain chain or else every chain
+		adminCall(*admin, args[0], func(ctx context.Context, a types.AdminServiceClient) (interface{}, error) {
+			return a.ListUpstreams(ctx, &types.ListUpstreamsRequest{ChainId: *chainID})
+		})
+
+	case "ListCaches":
+		// Call the ListCaches admin method, for the -chain chain or else every chain
+		adminCall(*admin, args[0], func(ctx context.Context, a types.AdminServiceClient) (interface{}, error) {
+			return a.ListCaches(ctx, &types.ListCachesRequest{ChainId: *chainID})
+		})
+
+	case "FlushCaches":
+		// Call the FlushCaches admin method with the cache names, every cache when none is given
+		adminCall(*admin, args[0], func(ctx context.Context, a types.AdminServiceClient) (interface{}, error) {
+			return a.FlushCaches(ctx, &types.FlushCachesRequest{ChainId: *chainID, Names: args[1:]})
+		})
+
+	case "GetConfig":
+		// Call the GetConfig admin method and print the yaml
+		conn, err := grpc.Dial(*admin, grpc.WithInsecure(), unixDialer())
+		if err != nil {
+			log.Fatalf("dial err: %v", err)
+		}
+		defer conn.Close()
+		r, err := types.NewAdminServiceClient(conn).GetConfig(context.Background(), &types.GetConfigRequest{})
+		if err != nil {
+			log.Fatalf("GetConfig err: %v", err)
+		}
+		fmt.Print(r.Yaml)
+
+	case "SetLogLevel":
+		// Call the SetLogLevel admin method
+		if len(args) < 2 {
+			fmt.Println("level should be oneof: debug, info, warn, error")
+			return
+		}
+		adminCall(*admin, args[0], func(ctx context.Context, a types.AdminServiceClient) (interface{}, error) {
+			return a.SetLogLevel(ctx, &types.SetLogLevelRequest{Level: args[1]})
+		})
+
+	case "ReconnectUpstreams":
+		// Call the ReconnectUpstreams admin method, for the -chain chain or else every chain
+		adminCall(*admin, args[0], func(ctx context.Context, a types.AdminServiceClient) (interface{}, error) {
+			return a.ReconnectUpstreams(ctx, &types.ReconnectUpstreamsRequest{ChainId: *chainID})
+		})
 	default:
 		// If the command is not recognized, print the available commands to the user
-		fmt.Println("command should be oneof: GetNodeInfo,GetSyncing,GetLatestBlock,GetBlockByHeight,GetLatestValidatorSet,GetValidatorSetByHeight, GetABCIInfo, GetStatusInfo")
+		fmt.Println("command should be oneof: GetNodeInfo,GetSyncing,GetLatestBlock,GetBlockByHeight,GetBlockTxs,GetTx,GetTxsByHeight,SearchTxs,GetBlockResults,GetLatestValidatorSet,GetValidatorSetByHeight,DiffValidatorSets,GetValidatorSetStats,GetValidatorUptime,GetMissedBlocks,GetProposers,VerifyCommit, GetABCIInfo, GetStatusInfo, ListUpstreams, ListCaches, FlushCaches, GetConfig, SetLogLevel, ReconnectUpstreams")
 	}
 }
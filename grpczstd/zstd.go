@@ -0,0 +1,85 @@
+// This package registers a zstd compressor for grpc, picked by a call with grpc.UseCompressor(grpczstd.Name).
+//
+// Importing it is enough for a server to accept zstd calls and answer them with zstd.
+package grpczstd
+
+import (
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+)
+
+// Name is the name registered for the zstd compressor
+const Name = "zstd"
+
+func init() {
+	c := &compressor{}
+	c.encoders.New = func() interface{} {
+		enc, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			panic(err)
+		}
+		return &writer{Encoder: enc, pool: &c.encoders}
+	}
+	c.decoders.New = func() interface{} {
+		// a single goroutine decodes a stream synchronously
+		dec, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			panic(err)
+		}
+		return &reader{Decoder: dec, pool: &c.decoders}
+	}
+	encoding.RegisterCompressor(c)
+}
+
+// compressor pools its encoders and decoders like the grpc gzip one
+type compressor struct {
+	encoders sync.Pool
+	decoders sync.Pool
+}
+
+// writer returns its encoder to the pool once closed
+type writer struct {
+	*zstd.Encoder
+	pool *sync.Pool
+}
+
+// reader returns its decoder to the pool once the stream is read
+type reader struct {
+	*zstd.Decoder
+	pool *sync.Pool
+}
+
+func (c *compressor) Name() string {
+	return Name
+}
+
+func (c *compressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	z := c.encoders.Get().(*writer)
+	z.Encoder.Reset(w)
+	return z, nil
+}
+
+func (z *writer) Close() error {
+	defer z.pool.Put(z)
+	return z.Encoder.Close()
+}
+
+func (c *compressor) Decompress(r io.Reader) (io.Reader, error) {
+	z := c.decoders.Get().(*reader)
+	if err := z.Decoder.Reset(r); err != nil {
+		c.decoders.Put(z)
+		return nil, err
+	}
+	return z, nil
+}
+
+func (z *reader) Read(p []byte) (int, error) {
+	n, err := z.Decoder.Read(p)
+	if err == io.EOF {
+		z.pool.Put(z)
+	}
+	return n, err
+}
@@ -0,0 +1,64 @@
+// This file contains tests for the zstd compressor.
+package grpczstd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// TestRoundTrip tests that concurrent calls compress and decompress with the pooled encoders and decoders
+func TestRoundTrip(t *testing.T) {
+	c := encoding.GetCompressor(Name)
+	if c == nil {
+		t.Fatal("expected the zstd compressor registered")
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			msg := bytes.Repeat([]byte{byte(i), 'b', 'l', 'o', 'c', 'k'}, 10000)
+			for j := 0; j < 3; j++ {
+				var buf bytes.Buffer
+				w, err := c.Compress(&buf)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				w.Write(msg)
+				if err := w.Close(); err != nil {
+					t.Error(err)
+					return
+				}
+				if buf.Len() >= len(msg)/10 {
+					t.Errorf("expected the message compressed, got %d bytes of %d", buf.Len(), len(msg))
+				}
+				r, err := c.Decompress(&buf)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				out, err := ioutil.ReadAll(r)
+				if err != nil || !bytes.Equal(out, msg) {
+					t.Errorf("expected the message back, got %d bytes and %v", len(out), err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// TestDecompressInvalid tests that a corrupted stream fails
+func TestDecompressInvalid(t *testing.T) {
+	r, err := encoding.GetCompressor(Name).Decompress(bytes.NewReader([]byte("not zstd")))
+	if err == nil {
+		_, err = ioutil.ReadAll(r)
+	}
+	if err == nil {
+		t.Error("expected an error for a stream that is not zstd")
+	}
+}
@@ -29,10 +29,32 @@ type GrpcQueryServiceClient interface {
 	GetLatestBlock(ctx context.Context, in *GetLatestBlockRequest, opts ...grpc.CallOption) (*GetLatestBlockResponse, error)
 	// GetBlockByHeight queries block for given height.
 	GetBlockByHeight(ctx context.Context, in *GetBlockByHeightRequest, opts ...grpc.CallOption) (*GetBlockByHeightResponse, error)
+	// GetBlockTxs returns the decoded transactions of the block at a given height.
+	GetBlockTxs(ctx context.Context, in *GetBlockTxsRequest, opts ...grpc.CallOption) (*GetBlockTxsResponse, error)
+	// GetTx returns the decoded transaction and its execution result for a given hash.
+	GetTx(ctx context.Context, in *GetTxRequest, opts ...grpc.CallOption) (*GetTxResponse, error)
+	// GetTxsByHeight returns the decoded transactions and execution results of a given block.
+	GetTxsByHeight(ctx context.Context, in *GetTxsByHeightRequest, opts ...grpc.CallOption) (*GetTxsByHeightResponse, error)
+	// SearchTxs returns the decoded transactions matching a tendermint event query.
+	SearchTxs(ctx context.Context, in *SearchTxsRequest, opts ...grpc.CallOption) (*SearchTxsResponse, error)
+	// GetBlockResults returns the ABCI results of the block at a given height.
+	GetBlockResults(ctx context.Context, in *GetBlockResultsRequest, opts ...grpc.CallOption) (*GetBlockResultsResponse, error)
 	// GetLatestValidatorSet queries latest validator-set.
 	GetLatestValidatorSet(ctx context.Context, in *GetLatestValidatorSetRequest, opts ...grpc.CallOption) (*GetLatestValidatorSetResponse, error)
 	// GetValidatorSetByHeight queries validator-set at a given height.
 	GetValidatorSetByHeight(ctx context.Context, in *GetValidatorSetByHeightRequest, opts ...grpc.CallOption) (*GetValidatorSetByHeightResponse, error)
+	// DiffValidatorSets reports the validators added, removed and changed between two heights.
+	DiffValidatorSets(ctx context.Context, in *DiffValidatorSetsRequest, opts ...grpc.CallOption) (*DiffValidatorSetsResponse, error)
+	// GetValidatorSetStats returns decentralization metrics of the validator-set at a given height.
+	GetValidatorSetStats(ctx context.Context, in *GetValidatorSetStatsRequest, opts ...grpc.CallOption) (*GetValidatorSetStatsResponse, error)
+	// GetValidatorUptime returns how many blocks a validator signed between two heights.
+	GetValidatorUptime(ctx context.Context, in *GetValidatorUptimeRequest, opts ...grpc.CallOption) (*GetValidatorUptimeResponse, error)
+	// GetMissedBlocks returns the signed and missed blocks of every validator over a window of heights.
+	GetMissedBlocks(ctx context.Context, in *GetMissedBlocksRequest, opts ...grpc.CallOption) (*GetMissedBlocksResponse, error)
+	// GetProposers returns the proposer of every block between two heights and how often each validator proposed.
+	GetProposers(ctx context.Context, in *GetProposersRequest, opts ...grpc.CallOption) (*GetProposersResponse, error)
+	// VerifyCommit checks the signatures of the commit for a height against the validator-set at that height.
+	VerifyCommit(ctx context.Context, in *VerifyCommitRequest, opts ...grpc.CallOption) (*VerifyCommitResponse, error)
 }
 
 type grpcQueryServiceClient struct {
@@ -97,6 +119,51 @@ func (c *grpcQueryServiceClient) GetBlockByHeight(ctx context.Context, in *GetBl
 	return out, nil
 }
 
+func (c *grpcQueryServiceClient) GetBlockTxs(ctx context.Context, in *GetBlockTxsRequest, opts ...grpc.CallOption) (*GetBlockTxsResponse, error) {
+	out := new(GetBlockTxsResponse)
+	err := c.cc.Invoke(ctx, "/proto.GrpcQueryService/GetBlockTxs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grpcQueryServiceClient) GetTx(ctx context.Context, in *GetTxRequest, opts ...grpc.CallOption) (*GetTxResponse, error) {
+	out := new(GetTxResponse)
+	err := c.cc.Invoke(ctx, "/proto.GrpcQueryService/GetTx", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grpcQueryServiceClient) GetTxsByHeight(ctx context.Context, in *GetTxsByHeightRequest, opts ...grpc.CallOption) (*GetTxsByHeightResponse, error) {
+	out := new(GetTxsByHeightResponse)
+	err := c.cc.Invoke(ctx, "/proto.GrpcQueryService/GetTxsByHeight", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grpcQueryServiceClient) SearchTxs(ctx context.Context, in *SearchTxsRequest, opts ...grpc.CallOption) (*SearchTxsResponse, error) {
+	out := new(SearchTxsResponse)
+	err := c.cc.Invoke(ctx, "/proto.GrpcQueryService/SearchTxs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grpcQueryServiceClient) GetBlockResults(ctx context.Context, in *GetBlockResultsRequest, opts ...grpc.CallOption) (*GetBlockResultsResponse, error) {
+	out := new(GetBlockResultsResponse)
+	err := c.cc.Invoke(ctx, "/proto.GrpcQueryService/GetBlockResults", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *grpcQueryServiceClient) GetLatestValidatorSet(ctx context.Context, in *GetLatestValidatorSetRequest, opts ...grpc.CallOption) (*GetLatestValidatorSetResponse, error) {
 	out := new(GetLatestValidatorSetResponse)
 	err := c.cc.Invoke(ctx, "/proto.GrpcQueryService/GetLatestValidatorSet", in, out, opts...)
@@ -115,6 +182,60 @@ func (c *grpcQueryServiceClient) GetValidatorSetByHeight(ctx context.Context, in
 	return out, nil
 }
 
+func (c *grpcQueryServiceClient) DiffValidatorSets(ctx context.Context, in *DiffValidatorSetsRequest, opts ...grpc.CallOption) (*DiffValidatorSetsResponse, error) {
+	out := new(DiffValidatorSetsResponse)
+	err := c.cc.Invoke(ctx, "/proto.GrpcQueryService/DiffValidatorSets", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grpcQueryServiceClient) GetValidatorSetStats(ctx context.Context, in *GetValidatorSetStatsRequest, opts ...grpc.CallOption) (*GetValidatorSetStatsResponse, error) {
+	out := new(GetValidatorSetStatsResponse)
+	err := c.cc.Invoke(ctx, "/proto.GrpcQueryService/GetValidatorSetStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grpcQueryServiceClient) GetValidatorUptime(ctx context.Context, in *GetValidatorUptimeRequest, opts ...grpc.CallOption) (*GetValidatorUptimeResponse, error) {
+	out := new(GetValidatorUptimeResponse)
+	err := c.cc.Invoke(ctx, "/proto.GrpcQueryService/GetValidatorUptime", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grpcQueryServiceClient) GetMissedBlocks(ctx context.Context, in *GetMissedBlocksRequest, opts ...grpc.CallOption) (*GetMissedBlocksResponse, error) {
+	out := new(GetMissedBlocksResponse)
+	err := c.cc.Invoke(ctx, "/proto.GrpcQueryService/GetMissedBlocks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grpcQueryServiceClient) GetProposers(ctx context.Context, in *GetProposersRequest, opts ...grpc.CallOption) (*GetProposersResponse, error) {
+	out := new(GetProposersResponse)
+	err := c.cc.Invoke(ctx, "/proto.GrpcQueryService/GetProposers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grpcQueryServiceClient) VerifyCommit(ctx context.Context, in *VerifyCommitRequest, opts ...grpc.CallOption) (*VerifyCommitResponse, error) {
+	out := new(VerifyCommitResponse)
+	err := c.cc.Invoke(ctx, "/proto.GrpcQueryService/VerifyCommit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // GrpcQueryServiceServer is the server API for GrpcQueryService service.
 // All implementations must embed UnimplementedGrpcQueryServiceServer
 // for forward compatibility
@@ -130,10 +251,32 @@ type GrpcQueryServiceServer interface {
 	GetLatestBlock(context.Context, *GetLatestBlockRequest) (*GetLatestBlockResponse, error)
 	// GetBlockByHeight queries block for given height.
 	GetBlockByHeight(context.Context, *GetBlockByHeightRequest) (*GetBlockByHeightResponse, error)
+	// GetBlockTxs returns the decoded transactions of the block at a given height.
+	GetBlockTxs(context.Context, *GetBlockTxsRequest) (*GetBlockTxsResponse, error)
+	// GetTx returns the decoded transaction and its execution result for a given hash.
+	GetTx(context.Context, *GetTxRequest) (*GetTxResponse, error)
+	// GetTxsByHeight returns the decoded transactions and execution results of a given block.
+	GetTxsByHeight(context.Context, *GetTxsByHeightRequest) (*GetTxsByHeightResponse, error)
+	// SearchTxs returns the decoded transactions matching a tendermint event query.
+	SearchTxs(context.Context, *SearchTxsRequest) (*SearchTxsResponse, error)
+	// GetBlockResults returns the ABCI results of the block at a given height.
+	GetBlockResults(context.Context, *GetBlockResultsRequest) (*GetBlockResultsResponse, error)
 	// GetLatestValidatorSet queries latest validator-set.
 	GetLatestValidatorSet(context.Context, *GetLatestValidatorSetRequest) (*GetLatestValidatorSetResponse, error)
 	// GetValidatorSetByHeight queries validator-set at a given height.
 	GetValidatorSetByHeight(context.Context, *GetValidatorSetByHeightRequest) (*GetValidatorSetByHeightResponse, error)
+	// DiffValidatorSets reports the validators added, removed and changed between two heights.
+	DiffValidatorSets(context.Context, *DiffValidatorSetsRequest) (*DiffValidatorSetsResponse, error)
+	// GetValidatorSetStats returns decentralization metrics of the validator-set at a given height.
+	GetValidatorSetStats(context.Context, *GetValidatorSetStatsRequest) (*GetValidatorSetStatsResponse, error)
+	// GetValidatorUptime returns how many blocks a validator signed between two heights.
+	GetValidatorUptime(context.Context, *GetValidatorUptimeRequest) (*GetValidatorUptimeResponse, error)
+	// GetMissedBlocks returns the signed and missed blocks of every validator over a window of heights.
+	GetMissedBlocks(context.Context, *GetMissedBlocksRequest) (*GetMissedBlocksResponse, error)
+	// GetProposers returns the proposer of every block between two heights and how often each validator proposed.
+	GetProposers(context.Context, *GetProposersRequest) (*GetProposersResponse, error)
+	// VerifyCommit checks the signatures of the commit for a height against the validator-set at that height.
+	VerifyCommit(context.Context, *VerifyCommitRequest) (*VerifyCommitResponse, error)
 	mustEmbedUnimplementedGrpcQueryServiceServer()
 }
 
@@ -159,12 +302,45 @@ func (UnimplementedGrpcQueryServiceServer) GetLatestBlock(context.Context, *GetL
 func (UnimplementedGrpcQueryServiceServer) GetBlockByHeight(context.Context, *GetBlockByHeightRequest) (*GetBlockByHeightResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetBlockByHeight not implemented")
 }
+func (UnimplementedGrpcQueryServiceServer) GetBlockTxs(context.Context, *GetBlockTxsRequest) (*GetBlockTxsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockTxs not implemented")
+}
+func (UnimplementedGrpcQueryServiceServer) GetTx(context.Context, *GetTxRequest) (*GetTxResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTx not implemented")
+}
+func (UnimplementedGrpcQueryServiceServer) GetTxsByHeight(context.Context, *GetTxsByHeightRequest) (*GetTxsByHeightResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTxsByHeight not implemented")
+}
+func (UnimplementedGrpcQueryServiceServer) SearchTxs(context.Context, *SearchTxsRequest) (*SearchTxsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchTxs not implemented")
+}
+func (UnimplementedGrpcQueryServiceServer) GetBlockResults(context.Context, *GetBlockResultsRequest) (*GetBlockResultsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockResults not implemented")
+}
 func (UnimplementedGrpcQueryServiceServer) GetLatestValidatorSet(context.Context, *GetLatestValidatorSetRequest) (*GetLatestValidatorSetResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetLatestValidatorSet not implemented")
 }
 func (UnimplementedGrpcQueryServiceServer) GetValidatorSetByHeight(context.Context, *GetValidatorSetByHeightRequest) (*GetValidatorSetByHeightResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetValidatorSetByHeight not implemented")
 }
+func (UnimplementedGrpcQueryServiceServer) DiffValidatorSets(context.Context, *DiffValidatorSetsRequest) (*DiffValidatorSetsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DiffValidatorSets not implemented")
+}
+func (UnimplementedGrpcQueryServiceServer) GetValidatorSetStats(context.Context, *GetValidatorSetStatsRequest) (*GetValidatorSetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetValidatorSetStats not implemented")
+}
+func (UnimplementedGrpcQueryServiceServer) GetValidatorUptime(context.Context, *GetValidatorUptimeRequest) (*GetValidatorUptimeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetValidatorUptime not implemented")
+}
+func (UnimplementedGrpcQueryServiceServer) GetMissedBlocks(context.Context, *GetMissedBlocksRequest) (*GetMissedBlocksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMissedBlocks not implemented")
+}
+func (UnimplementedGrpcQueryServiceServer) GetProposers(context.Context, *GetProposersRequest) (*GetProposersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProposers not implemented")
+}
+func (UnimplementedGrpcQueryServiceServer) VerifyCommit(context.Context, *VerifyCommitRequest) (*VerifyCommitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyCommit not implemented")
+}
 func (UnimplementedGrpcQueryServiceServer) mustEmbedUnimplementedGrpcQueryServiceServer() {}
 
 // UnsafeGrpcQueryServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -286,6 +462,96 @@ func _GrpcQueryService_GetBlockByHeight_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GrpcQueryService_GetBlockTxs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockTxsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrpcQueryServiceServer).GetBlockTxs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.GrpcQueryService/GetBlockTxs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrpcQueryServiceServer).GetBlockTxs(ctx, req.(*GetBlockTxsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrpcQueryService_GetTx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrpcQueryServiceServer).GetTx(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.GrpcQueryService/GetTx",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrpcQueryServiceServer).GetTx(ctx, req.(*GetTxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrpcQueryService_GetTxsByHeight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTxsByHeightRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrpcQueryServiceServer).GetTxsByHeight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.GrpcQueryService/GetTxsByHeight",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrpcQueryServiceServer).GetTxsByHeight(ctx, req.(*GetTxsByHeightRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrpcQueryService_SearchTxs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchTxsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrpcQueryServiceServer).SearchTxs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.GrpcQueryService/SearchTxs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrpcQueryServiceServer).SearchTxs(ctx, req.(*SearchTxsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrpcQueryService_GetBlockResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockResultsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrpcQueryServiceServer).GetBlockResults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.GrpcQueryService/GetBlockResults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrpcQueryServiceServer).GetBlockResults(ctx, req.(*GetBlockResultsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GrpcQueryService_GetLatestValidatorSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetLatestValidatorSetRequest)
 	if err := dec(in); err != nil {
@@ -322,6 +588,114 @@ func _GrpcQueryService_GetValidatorSetByHeight_Handler(srv interface{}, ctx cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GrpcQueryService_DiffValidatorSets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffValidatorSetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrpcQueryServiceServer).DiffValidatorSets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.GrpcQueryService/DiffValidatorSets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrpcQueryServiceServer).DiffValidatorSets(ctx, req.(*DiffValidatorSetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrpcQueryService_GetValidatorSetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetValidatorSetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrpcQueryServiceServer).GetValidatorSetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.GrpcQueryService/GetValidatorSetStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrpcQueryServiceServer).GetValidatorSetStats(ctx, req.(*GetValidatorSetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrpcQueryService_GetValidatorUptime_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetValidatorUptimeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrpcQueryServiceServer).GetValidatorUptime(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.GrpcQueryService/GetValidatorUptime",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrpcQueryServiceServer).GetValidatorUptime(ctx, req.(*GetValidatorUptimeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrpcQueryService_GetMissedBlocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMissedBlocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrpcQueryServiceServer).GetMissedBlocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.GrpcQueryService/GetMissedBlocks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrpcQueryServiceServer).GetMissedBlocks(ctx, req.(*GetMissedBlocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrpcQueryService_GetProposers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProposersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrpcQueryServiceServer).GetProposers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.GrpcQueryService/GetProposers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrpcQueryServiceServer).GetProposers(ctx, req.(*GetProposersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrpcQueryService_VerifyCommit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyCommitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrpcQueryServiceServer).VerifyCommit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.GrpcQueryService/VerifyCommit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrpcQueryServiceServer).VerifyCommit(ctx, req.(*VerifyCommitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // GrpcQueryService_ServiceDesc is the grpc.ServiceDesc for GrpcQueryService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -353,6 +727,26 @@ var GrpcQueryService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetBlockByHeight",
 			Handler:    _GrpcQueryService_GetBlockByHeight_Handler,
 		},
+		{
+			MethodName: "GetBlockTxs",
+			Handler:    _GrpcQueryService_GetBlockTxs_Handler,
+		},
+		{
+			MethodName: "GetTx",
+			Handler:    _GrpcQueryService_GetTx_Handler,
+		},
+		{
+			MethodName: "GetTxsByHeight",
+			Handler:    _GrpcQueryService_GetTxsByHeight_Handler,
+		},
+		{
+			MethodName: "SearchTxs",
+			Handler:    _GrpcQueryService_SearchTxs_Handler,
+		},
+		{
+			MethodName: "GetBlockResults",
+			Handler:    _GrpcQueryService_GetBlockResults_Handler,
+		},
 		{
 			MethodName: "GetLatestValidatorSet",
 			Handler:    _GrpcQueryService_GetLatestValidatorSet_Handler,
@@ -361,6 +755,308 @@ var GrpcQueryService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetValidatorSetByHeight",
 			Handler:    _GrpcQueryService_GetValidatorSetByHeight_Handler,
 		},
+		{
+			MethodName: "DiffValidatorSets",
+			Handler:    _GrpcQueryService_DiffValidatorSets_Handler,
+		},
+		{
+			MethodName: "GetValidatorSetStats",
+			Handler:    _GrpcQueryService_GetValidatorSetStats_Handler,
+		},
+		{
+			MethodName: "GetValidatorUptime",
+			Handler:    _GrpcQueryService_GetValidatorUptime_Handler,
+		},
+		{
+			MethodName: "GetMissedBlocks",
+			Handler:    _GrpcQueryService_GetMissedBlocks_Handler,
+		},
+		{
+			MethodName: "GetProposers",
+			Handler:    _GrpcQueryService_GetProposers_Handler,
+		},
+		{
+			MethodName: "VerifyCommit",
+			Handler:    _GrpcQueryService_VerifyCommit_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "rpc.proto",
+}
+
+// AdminServiceClient is the client API for AdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AdminServiceClient interface {
+	// ListUpstreams probes the upstreams of every chain and reports their health and latency
+	ListUpstreams(ctx context.Context, in *ListUpstreamsRequest, opts ...grpc.CallOption) (*ListUpstreamsResponse, error)
+	// ListCaches reports the size of the caches of every chain
+	ListCaches(ctx context.Context, in *ListCachesRequest, opts ...grpc.CallOption) (*ListCachesResponse, error)
+	// FlushCaches empties caches
+	FlushCaches(ctx context.Context, in *FlushCachesRequest, opts ...grpc.CallOption) (*FlushCachesResponse, error)
+	// GetConfig dumps the effective configuration as yaml
+	GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigResponse, error)
+	// SetLogLevel changes the log level
+	SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error)
+	// ReconnectUpstreams closes the connections kept to the upstreams, which are opened again on their next use
+	ReconnectUpstreams(ctx context.Context, in *ReconnectUpstreamsRequest, opts ...grpc.CallOption) (*ReconnectUpstreamsResponse, error)
+}
+
+type adminServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminServiceClient(cc grpc.ClientConnInterface) AdminServiceClient {
+	return &adminServiceClient{cc}
+}
+
+func (c *adminServiceClient) ListUpstreams(ctx context.Context, in *ListUpstreamsRequest, opts ...grpc.CallOption) (*ListUpstreamsResponse, error) {
+	out := new(ListUpstreamsResponse)
+	err := c.cc.Invoke(ctx, "/proto.AdminService/ListUpstreams", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ListCaches(ctx context.Context, in *ListCachesRequest, opts ...grpc.CallOption) (*ListCachesResponse, error) {
+	out := new(ListCachesResponse)
+	err := c.cc.Invoke(ctx, "/proto.AdminService/ListCaches", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) FlushCaches(ctx context.Context, in *FlushCachesRequest, opts ...grpc.CallOption) (*FlushCachesResponse, error) {
+	out := new(FlushCachesResponse)
+	err := c.cc.Invoke(ctx, "/proto.AdminService/FlushCaches", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigResponse, error) {
+	out := new(GetConfigResponse)
+	err := c.cc.Invoke(ctx, "/proto.AdminService/GetConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error) {
+	out := new(SetLogLevelResponse)
+	err := c.cc.Invoke(ctx, "/proto.AdminService/SetLogLevel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ReconnectUpstreams(ctx context.Context, in *ReconnectUpstreamsRequest, opts ...grpc.CallOption) (*ReconnectUpstreamsResponse, error) {
+	out := new(ReconnectUpstreamsResponse)
+	err := c.cc.Invoke(ctx, "/proto.AdminService/ReconnectUpstreams", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServiceServer is the server API for AdminService service.
+// All implementations must embed UnimplementedAdminServiceServer
+// for forward compatibility
+type AdminServiceServer interface {
+	// ListUpstreams probes the upstreams of every chain and reports their health and latency
+	ListUpstreams(context.Context, *ListUpstreamsRequest) (*ListUpstreamsResponse, error)
+	// ListCaches reports the size of the caches of every chain
+	ListCaches(context.Context, *ListCachesRequest) (*ListCachesResponse, error)
+	// FlushCaches empties caches
+	FlushCaches(context.Context, *FlushCachesRequest) (*FlushCachesResponse, error)
+	// GetConfig dumps the effective configuration as yaml
+	GetConfig(context.Context, *GetConfigRequest) (*GetConfigResponse, error)
+	// SetLogLevel changes the log level
+	SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error)
+	// ReconnectUpstreams closes the connections kept to the upstreams, which are opened again on their next use
+	ReconnectUpstreams(context.Context, *ReconnectUpstreamsRequest) (*ReconnectUpstreamsResponse, error)
+	mustEmbedUnimplementedAdminServiceServer()
+}
+
+// UnimplementedAdminServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedAdminServiceServer struct {
+}
+
+func (UnimplementedAdminServiceServer) ListUpstreams(context.Context, *ListUpstreamsRequest) (*ListUpstreamsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUpstreams not implemented")
+}
+func (UnimplementedAdminServiceServer) ListCaches(context.Context, *ListCachesRequest) (*ListCachesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCaches not implemented")
+}
+func (UnimplementedAdminServiceServer) FlushCaches(context.Context, *FlushCachesRequest) (*FlushCachesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FlushCaches not implemented")
+}
+func (UnimplementedAdminServiceServer) GetConfig(context.Context, *GetConfigRequest) (*GetConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConfig not implemented")
+}
+func (UnimplementedAdminServiceServer) SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLogLevel not implemented")
+}
+func (UnimplementedAdminServiceServer) ReconnectUpstreams(context.Context, *ReconnectUpstreamsRequest) (*ReconnectUpstreamsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReconnectUpstreams not implemented")
+}
+func (UnimplementedAdminServiceServer) mustEmbedUnimplementedAdminServiceServer() {}
+
+// UnsafeAdminServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminServiceServer will
+// result in compilation errors.
+type UnsafeAdminServiceServer interface {
+	mustEmbedUnimplementedAdminServiceServer()
+}
+
+func RegisterAdminServiceServer(s grpc.ServiceRegistrar, srv AdminServiceServer) {
+	s.RegisterService(&AdminService_ServiceDesc, srv)
+}
+
+func _AdminService_ListUpstreams_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUpstreamsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ListUpstreams(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.AdminService/ListUpstreams",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ListUpstreams(ctx, req.(*ListUpstreamsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ListCaches_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCachesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ListCaches(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.AdminService/ListCaches",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ListCaches(ctx, req.(*ListCachesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_FlushCaches_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FlushCachesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).FlushCaches(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.AdminService/FlushCaches",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).FlushCaches(ctx, req.(*FlushCachesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_GetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).GetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.AdminService/GetConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).GetConfig(ctx, req.(*GetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.AdminService/SetLogLevel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).SetLogLevel(ctx, req.(*SetLogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ReconnectUpstreams_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReconnectUpstreamsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ReconnectUpstreams(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.AdminService/ReconnectUpstreams",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ReconnectUpstreams(ctx, req.(*ReconnectUpstreamsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AdminService_ServiceDesc is the grpc.ServiceDesc for AdminService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AdminService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.AdminService",
+	HandlerType: (*AdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListUpstreams",
+			Handler:    _AdminService_ListUpstreams_Handler,
+		},
+		{
+			MethodName: "ListCaches",
+			Handler:    _AdminService_ListCaches_Handler,
+		},
+		{
+			MethodName: "FlushCaches",
+			Handler:    _AdminService_FlushCaches_Handler,
+		},
+		{
+			MethodName: "GetConfig",
+			Handler:    _AdminService_GetConfig_Handler,
+		},
+		{
+			MethodName: "SetLogLevel",
+			Handler:    _AdminService_SetLogLevel_Handler,
+		},
+		{
+			MethodName: "ReconnectUpstreams",
+			Handler:    _AdminService_ReconnectUpstreams_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "rpc.proto",
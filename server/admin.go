@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	types "grpc_server4/proto/generated"
+	"grpc_server4/tmrpc"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v2"
+)
+
+// ADMIN_PROBE_TIMEOUT bounds the probe of an upstream by ListUpstreams
+const ADMIN_PROBE_TIMEOUT = 5 * time.Second
+
+// CACHE_LIGHT_CLIENT and CACHE_LATEST_BLOCK are the names of the caches of a chain
+const (
+	CACHE_LIGHT_CLIENT = "light_client"
+	CACHE_LATEST_BLOCK = "latest_block"
+)
+
+// LoadedConfig is the running configuration, replaced by every reload
+var LoadedConfig = &Config{}
+
+// adminServer implements the AdminService
+type adminServer struct {
+	types.UnimplementedAdminServiceServer
+}
+
+// adminChains returns the chains an admin request applies to, every chain when chainID is empty, by chain id
+func adminChains(chainID string) ([]*Chain, error) {
+	if chainID == "" {
+		return chainList(), nil
+	}
+	chain, ok := lookupChain(chainID)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown chain %q", chainID)
+	}
+	return []*Chain{chain}, nil
+}
+
+// ListUpstreams probes every gRPC upstream and tendermint RPC of the chains at once, and reports the websockets
+func (s *adminServer) ListUpstreams(ctx context.Context, req *types.ListUpstreamsRequest) (*types.ListUpstreamsResponse, error) {
+	chains, err := adminChains(req.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	ans := &types.ListUpstreamsResponse{}
+	var probes []func()
+	for _, chain := range chains {
+		upstream := &types.UpstreamStatus{ChainId: chain.ChainID, Kind: "grpc", Address: chain.GRPCAddress}
+		ans.Upstreams = append(ans.Upstreams, upstream)
+		probes = append(probes, func() { probeGRPC(ctx, upstream) })
+		for _, address := range chain.QuorumUpstreams {
+			upstream := &types.UpstreamStatus{ChainId: chain.ChainID, Kind: "quorum", Address: address}
+			ans.Upstreams = append(ans.Upstreams, upstream)
+			probes = append(probes, func() { probeGRPC(ctx, upstream) })
+		}
+		rpc := &types.UpstreamStatus{ChainId: chain.ChainID, Kind: "rpc", Address: chain.RPCAddress}
+		ans.Upstreams = append(ans.Upstreams, rpc)
+		node := chain.RPC
+		probes = append(probes, func() { probeRPC(ctx, node, rpc) })
+		ans.Upstreams = append(ans.Upstreams, websocketStatus(chain))
+	}
+	var wg sync.WaitGroup
+	for _, probe := range probes {
+		wg.Add(1)
+		go func(probe func()) {
+			defer wg.Done()
+			probe()
+		}(probe)
+	}
+	wg.Wait()
+	return ans, nil
+}
+
+// probeGRPC reads the latest height of a gRPC upstream from the first page of its latest validator-set
+func probeGRPC(ctx context.Context, upstream *types.UpstreamStatus) {
+	ctx, cancel := context.WithTimeout(ctx, ADMIN_PROBE_TIMEOUT)
+	defer cancel()
+	client, closeConn, err := dialUpstream(upstream.Address)
+	if err != nil {
+		upstream.Error = err.Error()
+		return
+	}
+	defer closeConn()
+	start := time.Now()
+	valSet, err := client.GetLatestValidatorSet(ctx, &tmservice.GetLatestValidatorSetRequest{
+		Pagination: &query.PageRequest{Limit: 1},
+	})
+	upstream.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		upstream.Error = err.Error()
+		return
+	}
+	upstream.Healthy = true
+	upstream.LatestHeight = valSet.BlockHeight
+}
+
+// probeRPC reads the latest height of a tendermint RPC from its status
+func probeRPC(ctx context.Context, node *tmrpc.Client, upstream *types.UpstreamStatus) {
+	ctx, cancel := context.WithTimeout(ctx, ADMIN_PROBE_TIMEOUT)
+	defer cancel()
+	start := time.Now()
+	result, err := node.Status(ctx)
+	upstream.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		upstream.Error = err.Error()
+		return
+	}
+	upstream.Healthy = true
+	upstream.LatestHeight = result.SyncInfo.LatestBlockHeight
+	upstream.CatchingUp = result.SyncInfo.CatchingUp
+}
+
+// websocketStatus reports the event subscription of a chain, healthy while it is connected
+func websocketStatus(chain *Chain) *types.UpstreamStatus {
+	upstream := &types.UpstreamStatus{ChainId: chain.ChainID, Kind: "websocket", Address: chain.RPCAddress}
+	upstream.Healthy = chain.Events.isConnected()
+	if !upstream.Healthy {
+		upstream.Error = "not connected"
+	}
+	if _, block := chain.Events.latest(); block != nil {
+		upstream.LatestHeight = block.Header.Height
+	}
+	return upstream
+}
+
+// ListCaches reports the light blocks of the light client and the pushed latest block of every chain
+func (s *adminServer) ListCaches(ctx context.Context, req *types.ListCachesRequest) (*types.ListCachesResponse, error) {
+	chains, err := adminChains(req.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	ans := &types.ListCachesResponse{}
+	for _, chain := range chains {
+		if chain.LightClient != nil {
+			ans.Caches = append(ans.Caches, &types.CacheInfo{ChainId: chain.ChainID, Name: CACHE_LIGHT_CLIENT, Entries: chain.LightClient.size()})
+		}
+		var entries int64
+		if _, block := chain.Events.latest(); block != nil {
+			entries = 1
+		}
+		ans.Caches = append(ans.Caches, &types.CacheInfo{ChainId: chain.ChainID, Name: CACHE_LATEST_BLOCK, Entries: entries})
+	}
+	return ans, nil
+}
+
+// FlushCaches empties the named caches of the chains
+func (s *adminServer) FlushCaches(ctx context.Context, req *types.FlushCachesRequest) (*types.FlushCachesResponse, error) {
+	names := map[string]bool{}
+	for _, name := range req.Names {
+		if name != CACHE_LIGHT_CLIENT && name != CACHE_LATEST_BLOCK {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown cache %q", name)
+		}
+		names[name] = true
+	}
+	chains, err := adminChains(req.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	ans := &types.FlushCachesResponse{}
+	for _, chain := range chains {
+		if chain.LightClient != nil && (len(names) == 0 || names[CACHE_LIGHT_CLIENT]) {
+			ans.Flushed = append(ans.Flushed, &types.CacheInfo{ChainId: chain.ChainID, Name: CACHE_LIGHT_CLIENT, Entries: chain.LightClient.flush()})
+		}
+		if len(names) == 0 || names[CACHE_LATEST_BLOCK] {
+			ans.Flushed = append(ans.Flushed, &types.CacheInfo{ChainId: chain.ChainID, Name: CACHE_LATEST_BLOCK, Entries: chain.Events.flush()})
+		}
+	}
+	return ans, nil
+}
+
+// GetConfig dumps the configuration with the chains actually served
+func (s *adminServer) GetConfig(ctx context.Context, req *types.GetConfigRequest) (*types.GetConfigResponse, error) {
+	registryMtx.RLock()
+	config, defaultChain := *LoadedConfig, DefaultChain
+	registryMtx.RUnlock()
+	if len(config.Chains) == 0 {
+		// the built-in chain serves every request
+		config.Chains, config.LightClient = []ChainConfig{defaultChain.ChainConfig}, LightClientConfig{}
+	}
+	config.DefaultChain = defaultChain.ChainID
+	config.LogLevel = currentLogLevel()
+	bz, err := yaml.Marshal(&config)
+	if err != nil {
+		return nil, err
+	}
+	return &types.GetConfigResponse{Yaml: string(bz), LogLevel: config.LogLevel}, nil
+}
+
+// SetLogLevel changes the log level
+func (s *adminServer) SetLogLevel(ctx context.Context, req *types.SetLogLevelRequest) (*types.SetLogLevelResponse, error) {
+	previous, err := setLogLevel(req.Level)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	logf("info", "log level changed from %s to %s", previous, req.Level)
+	return &types.SetLogLevelResponse{Previous: previous}, nil
+}
+
+// ReconnectUpstreams reconnects the websockets of the chains and closes the idle connections to the tendermint
+// RPCs. The gRPC upstreams need nothing, a connection is dialed for every request.
+func (s *adminServer) ReconnectUpstreams(ctx context.Context, req *types.ReconnectUpstreamsRequest) (*types.ReconnectUpstreamsResponse, error) {
+	chains, err := adminChains(req.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	ans := &types.ReconnectUpstreamsResponse{}
+	for _, chain := range chains {
+		chain.Events.ws.Reconnect()
+		ans.Reconnected = append(ans.Reconnected, chain.ChainID)
+	}
+	tmrpc.HTTPClient.CloseIdleConnections()
+	logf("info", "reconnected the upstreams of %v", ans.Reconnected)
+	return ans, nil
+}
+
+// serveAdmin serves the AdminService on its own listener
+func serveAdmin(listen ListenerConfig) error {
+	listener, err := listen.listen()
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	s := grpc.NewServer(grpc.UnaryInterceptor(logInterceptor))
+	types.RegisterAdminServiceServer(s, &adminServer{})
+	reflection.Register(s)
+	logf("info", "admin server is started on %s", listen.Address)
+	return s.Serve(listener)
+}
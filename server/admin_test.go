@@ -0,0 +1,223 @@
+// This file contains tests for the AdminService.
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	types "grpc_server4/proto/generated"
+	"grpc_server4/tmrpc"
+
+	tmtypes "github.com/tendermint/tendermint/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v2"
+)
+
+// TestAdminCaches tests that the caches of a chain are listed and flushed by name
+func TestAdminCaches(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+	defer InitCcontext()
+
+	chain := newTestChain(t, 4, 20)
+	block, err := tmtypes.BlockFromProto(chain.blocks[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	DefaultChain.LightClient = testLightClient(t, chain, 2)
+	if err := DefaultChain.LightClient.verifyHeader(context.Background(), chain.service(), chain.blocks[15].Header); err != nil {
+		t.Fatal(err)
+	}
+	DefaultChain.Events.handle(eventNewBlock(block))
+
+	s := &adminServer{}
+	caches, err := s.ListCaches(context.Background(), &types.ListCachesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(caches.Caches) != 2 || caches.Caches[0].Name != CACHE_LIGHT_CLIENT || caches.Caches[0].Entries != 2 || caches.Caches[1].Entries != 1 {
+		t.Fatalf("expected 2 light blocks and the latest block, got %v", caches.Caches)
+	}
+
+	if _, err := s.FlushCaches(context.Background(), &types.FlushCachesRequest{Names: []string{"blocks"}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an unknown cache, got %v", err)
+	}
+	flushed, err := s.FlushCaches(context.Background(), &types.FlushCachesRequest{Names: []string{CACHE_LATEST_BLOCK}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flushed.Flushed) != 1 || flushed.Flushed[0].Entries != 1 {
+		t.Errorf("expected the latest block flushed, got %v", flushed.Flushed)
+	}
+	if _, b := DefaultChain.Events.latest(); b != nil {
+		t.Error("expected no latest block after the flush")
+	}
+	if DefaultChain.LightClient.size() != 2 {
+		t.Error("expected the light client kept")
+	}
+	if _, err := s.FlushCaches(context.Background(), &types.FlushCachesRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if DefaultChain.LightClient.size() != 0 {
+		t.Errorf("expected no light block after flushing every cache, got %d", DefaultChain.LightClient.size())
+	}
+	// the next verification starts again from the trusted header
+	if err := DefaultChain.LightClient.verifyHeader(context.Background(), chain.service(), chain.blocks[15].Header); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.ListCaches(context.Background(), &types.ListCachesRequest{ChainId: "juno-1"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown chain, got %v", err)
+	}
+}
+
+// TestAdminCachesDuringVerification tests that the light client cache is listed and flushed while a verification
+// waits on the upstream, which then does not store the light blocks it verified from the flushed ones
+func TestAdminCachesDuringVerification(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+	defer InitCcontext()
+
+	chain := newTestChain(t, 4, 20)
+	DefaultChain.LightClient = testLightClient(t, chain, 2)
+	if err := DefaultChain.LightClient.verifyHeader(context.Background(), chain.service(), chain.blocks[15].Header); err != nil {
+		t.Fatal(err)
+	}
+	stalled := newStalledService(chain.service())
+	done := make(chan error)
+	go func() {
+		done <- DefaultChain.LightClient.verifyHeader(context.Background(), stalled, chain.blocks[18].Header)
+	}()
+	<-stalled.fetching
+
+	s := &adminServer{}
+	answered := make(chan error)
+	go func() {
+		caches, err := s.ListCaches(context.Background(), &types.ListCachesRequest{})
+		if err == nil && (len(caches.Caches) == 0 || caches.Caches[0].Entries != 2) {
+			err = fmt.Errorf("expected 2 light blocks, got %v", caches.Caches)
+		}
+		if err == nil {
+			_, err = s.FlushCaches(context.Background(), &types.FlushCachesRequest{Names: []string{CACHE_LIGHT_CLIENT}})
+		}
+		answered <- err
+	}()
+	select {
+	case err := <-answered:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the caches listed and flushed while the verification waits on the upstream")
+	}
+
+	close(stalled.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if n := DefaultChain.LightClient.size(); n != 0 {
+		t.Errorf("expected no light block stored from before the flush, got %d", n)
+	}
+}
+
+// eventNewBlock returns the NewBlock event of block
+func eventNewBlock(block *tmtypes.Block) tmrpc.Event {
+	return tmrpc.Event{Query: EVENT_QUERIES[0], Data: tmtypes.EventDataNewBlock{Block: block}}
+}
+
+// TestAdminLogLevel tests that the log level is changed and an unknown one rejected
+func TestAdminLogLevel(t *testing.T) {
+	defer setLogLevel(DEFAULT_LOG_LEVEL)
+
+	s := &adminServer{}
+	resp, err := s.SetLogLevel(context.Background(), &types.SetLogLevelRequest{Level: "debug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Previous != DEFAULT_LOG_LEVEL || currentLogLevel() != "debug" {
+		t.Errorf("expected the level changed from info to debug, got %s to %s", resp.Previous, currentLogLevel())
+	}
+	if _, err := s.SetLogLevel(context.Background(), &types.SetLogLevelRequest{Level: "trace"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an unknown level, got %v", err)
+	}
+}
+
+// TestAdminGetConfig tests that the config dump holds the chains actually served
+func TestAdminGetConfig(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+	defer func() { LoadedConfig = &Config{} }()
+
+	s := &adminServer{}
+	resp, err := s.GetConfig(context.Background(), &types.GetConfigRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config Config
+	if err := yaml.Unmarshal([]byte(resp.Yaml), &config); err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Chains) != 1 || config.Chains[0].ChainID != CHAIN_ID || config.DefaultChain != CHAIN_ID {
+		t.Errorf("expected the built-in chain, got %+v", config)
+	}
+	if resp.LogLevel != currentLogLevel() || config.LogLevel != resp.LogLevel {
+		t.Errorf("expected the log level %s, got %s", currentLogLevel(), resp.LogLevel)
+	}
+
+	LoadedConfig = &Config{Chains: testChainConfigs, Admin: AdminConfig{Listen: "localhost:9091"}}
+	resp, err = s.GetConfig(context.Background(), &types.GetConfigRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp.Yaml, "cosmoshub-4") || !strings.Contains(resp.Yaml, "localhost:9091") {
+		t.Errorf("expected the chain registry and the admin listener, got\n%s", resp.Yaml)
+	}
+}
+
+// TestAdminListUpstreams tests that every upstream of a chain is probed and an unreachable one reported
+func TestAdminListUpstreams(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+	defer InitCcontext()
+
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"sync_info":{"latest_block_height":"42","catching_up":true}}}`))
+	}))
+	defer node.Close()
+	configs := []ChainConfig{testChainConfigs[0], testChainConfigs[1]}
+	configs[0].GRPCAddress, configs[0].RPCAddress = "localhost:1", node.URL
+	if err := initChains(&Config{Chains: configs}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &adminServer{}
+	resp, err := s.ListUpstreams(context.Background(), &types.ListUpstreamsRequest{ChainId: "osmosis-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[string]*types.UpstreamStatus{}
+	for _, upstream := range resp.Upstreams {
+		if upstream.ChainId != "osmosis-1" {
+			t.Errorf("expected only osmosis-1, got %s", upstream.ChainId)
+		}
+		kinds[upstream.Kind] = upstream
+	}
+	if len(resp.Upstreams) != 3 || kinds["grpc"] == nil || kinds["rpc"] == nil || kinds["websocket"] == nil {
+		t.Fatalf("expected the grpc, rpc and websocket upstreams, got %v", resp.Upstreams)
+	}
+	if kinds["grpc"].Healthy || kinds["grpc"].Error == "" {
+		t.Errorf("expected the unreachable gRPC upstream unhealthy, got %v", kinds["grpc"])
+	}
+	if rpc := kinds["rpc"]; !rpc.Healthy || rpc.LatestHeight != 42 || !rpc.CatchingUp {
+		t.Errorf("expected the RPC at height 42 catching up, got %v", rpc)
+	}
+	if kinds["websocket"].Healthy {
+		t.Error("expected the websocket not connected")
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	types "grpc_server4/proto/generated"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetBlockResults returns the ABCI results of a block: the tx results, begin/end block events,
+// validator updates and consensus param updates, read from the block_results of the node at the chain RPC address
+func (s *server) GetBlockResults(ctx context.Context, req *types.GetBlockResultsRequest) (*types.GetBlockResultsResponse, error) {
+	if req.Height < 0 {
+		return nil, status.Error(codes.InvalidArgument, "height must not be negative")
+	}
+	// a nil height makes tendermint return the latest block results
+	var height *int64
+	if req.Height > 0 {
+		height = &req.Height
+	}
+	results, err := chainFromContext(ctx).RPC.BlockResults(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	return blockResults(results), nil
+}
+
+// blockResults converts the tendermint block_results into their proto representation
+func blockResults(results *coretypes.ResultBlockResults) *types.GetBlockResultsResponse {
+	ans := &types.GetBlockResultsResponse{
+		Height:           results.Height,
+		TxsResults:       make([]*types.TxExecResult, 0, len(results.TxsResults)),
+		BeginBlockEvents: events(results.BeginBlockEvents),
+		EndBlockEvents:   events(results.EndBlockEvents),
+		ValidatorUpdates: make([]*types.ValidatorUpdate, 0, len(results.ValidatorUpdates)),
+	}
+	for _, tx := range results.TxsResults {
+		ans.TxsResults = append(ans.TxsResults, &types.TxExecResult{
+			Code:      tx.Code,
+			Data:      tx.Data,
+			Log:       tx.Log,
+			Info:      tx.Info,
+			GasWanted: tx.GasWanted,
+			GasUsed:   tx.GasUsed,
+			Events:    events(tx.Events),
+			Codespace: tx.Codespace,
+		})
+	}
+	for _, v := range results.ValidatorUpdates {
+		update := &types.ValidatorUpdate{Power: v.Power}
+		if key := v.PubKey.GetEd25519(); key != nil {
+			update.PubKeyType = "ed25519"
+			update.PubKey = key
+		} else if key := v.PubKey.GetSecp256K1(); key != nil {
+			update.PubKeyType = "secp256k1"
+			update.PubKey = key
+		}
+		ans.ValidatorUpdates = append(ans.ValidatorUpdates, update)
+	}
+	if results.ConsensusParamUpdates != nil {
+		ans.ConsensusParamUpdates = consensusParamUpdates(results.ConsensusParamUpdates)
+	}
+	return ans
+}
+
+// consensusParamUpdates converts the consensus params changed by EndBlock, leaving unchanged groups unset
+func consensusParamUpdates(params *abci.ConsensusParams) *types.ConsensusParamUpdates {
+	ans := &types.ConsensusParamUpdates{}
+	if params.Block != nil {
+		ans.Block = &types.BlockParams{
+			MaxBytes: params.Block.MaxBytes,
+			MaxGas:   params.Block.MaxGas,
+		}
+	}
+	if params.Evidence != nil {
+		ans.Evidence = &types.EvidenceParams{
+			MaxAgeNumBlocks: params.Evidence.MaxAgeNumBlocks,
+			MaxAgeDuration:  params.Evidence.MaxAgeDuration.String(),
+			MaxBytes:        params.Evidence.MaxBytes,
+		}
+	}
+	if params.Validator != nil {
+		ans.Validator = &types.ValidatorParams{
+			PubKeyTypes: params.Validator.PubKeyTypes,
+		}
+	}
+	if params.Version != nil {
+		ans.Version = &types.VersionParams{
+			AppVersion: params.Version.AppVersion,
+		}
+	}
+	return ans
+}
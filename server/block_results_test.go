@@ -0,0 +1,53 @@
+// This file contains tests for the conversion of tendermint block_results used by GetBlockResults.
+package main
+
+import (
+	"testing"
+	"time"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/proto/tendermint/crypto"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+// TestBlockResults tests that tx results, block events, validator updates and param updates are all converted
+func TestBlockResults(t *testing.T) {
+	results := &coretypes.ResultBlockResults{
+		Height: 8700000,
+		TxsResults: []*abci.ResponseDeliverTx{
+			{Code: 0, GasUsed: 80000, Events: []abci.Event{{Type: "transfer"}}},
+			{Code: 11, Codespace: "sdk", Log: "out of gas"},
+		},
+		BeginBlockEvents: []abci.Event{{
+			Type:       "rewards",
+			Attributes: []abci.EventAttribute{{Key: []byte("amount"), Value: []byte("10uosmo")}},
+		}},
+		EndBlockEvents: []abci.Event{{Type: "epoch_end"}},
+		ValidatorUpdates: []abci.ValidatorUpdate{{
+			PubKey: crypto.PublicKey{Sum: &crypto.PublicKey_Ed25519{Ed25519: []byte{1, 2, 3}}},
+			Power:  0,
+		}},
+		ConsensusParamUpdates: &abci.ConsensusParams{
+			Evidence: &tmproto.EvidenceParams{MaxAgeNumBlocks: 100, MaxAgeDuration: 48 * time.Hour},
+		},
+	}
+
+	ans := blockResults(results)
+	if ans.Height != 8700000 || len(ans.TxsResults) != 2 || ans.TxsResults[1].Code != 11 || ans.TxsResults[0].Events[0].Type != "transfer" {
+		t.Errorf("unexpected tx results %v", ans.TxsResults)
+	}
+	if len(ans.BeginBlockEvents) != 1 || ans.BeginBlockEvents[0].Attributes[0].Value != "10uosmo" {
+		t.Errorf("unexpected begin block events %v", ans.BeginBlockEvents)
+	}
+	if len(ans.EndBlockEvents) != 1 || ans.EndBlockEvents[0].Type != "epoch_end" {
+		t.Errorf("unexpected end block events %v", ans.EndBlockEvents)
+	}
+	if len(ans.ValidatorUpdates) != 1 || ans.ValidatorUpdates[0].PubKeyType != "ed25519" {
+		t.Errorf("unexpected validator updates %v", ans.ValidatorUpdates)
+	}
+	params := ans.ConsensusParamUpdates
+	if params.Block != nil || params.Evidence.MaxAgeNumBlocks != 100 || params.Evidence.MaxAgeDuration != "48h0m0s" {
+		t.Errorf("unexpected consensus param updates %v", params)
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"grpc_server4/tmrpc"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/std"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/osmosis-labs/osmosis/v12/app"
+	"github.com/osmosis-labs/osmosis/v12/app/params"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// CHAIN_ID_HEADER is the metadata header selecting the chain of a request that does not set chain_id
+const CHAIN_ID_HEADER = "x-chain-id"
+
+// DEFAULT_ENCODING is the encoding of a chain whose config does not name one
+const DEFAULT_ENCODING = "osmosis"
+
+// ENCODINGS are the codecs a chain of the registry can use, by name.
+// The Osmosis codec also registers the standard cosmos-sdk and IBC messages, the cosmos one only the standard
+// cosmos-sdk types, for chains without the Osmosis modules.
+var ENCODINGS = map[string]func() params.EncodingConfig{
+	"osmosis": app.MakeEncodingConfig,
+	"cosmos":  makeCosmosEncodingConfig,
+}
+
+// makeCosmosEncodingConfig returns the codec of a plain cosmos-sdk chain: the std crypto and tx types with the auth,
+// bank and staking modules
+func makeCosmosEncodingConfig() params.EncodingConfig {
+	encodingConfig := params.MakeEncodingConfig()
+	std.RegisterLegacyAminoCodec(encodingConfig.Amino)
+	std.RegisterInterfaces(encodingConfig.InterfaceRegistry)
+	authtypes.RegisterInterfaces(encodingConfig.InterfaceRegistry)
+	banktypes.RegisterInterfaces(encodingConfig.InterfaceRegistry)
+	stakingtypes.RegisterInterfaces(encodingConfig.InterfaceRegistry)
+	return encodingConfig
+}
+
+// ChainConfig is an entry of the chain registry
+type ChainConfig struct {
+	ChainID     string `yaml:"chain_id"`
+	GRPCAddress string `yaml:"grpc_address"`
+	RPCAddress  string `yaml:"rpc_address"`
+	// Bech32Prefix is the account prefix, validator and consensus prefixes are derived from it
+	Bech32Prefix string            `yaml:"bech32_prefix"`
+	Encoding     string            `yaml:"encoding"`
+	LightClient  LightClientConfig `yaml:"light_client"`
+	// QuorumUpstreams are the gRPC addresses read along with GRPCAddress by quorum reads
+	QuorumUpstreams []string `yaml:"quorum_upstreams"`
+	// Quorum is the number of upstreams that must agree, a majority of them when zero
+	Quorum int `yaml:"quorum"`
+}
+
+// Chain is a chain of the registry with the client context built from its encoding
+type Chain struct {
+	ChainConfig
+	Context client.Context
+	// RPC is the tendermint JSON-RPC client of the node at RPCAddress
+	RPC *tmrpc.Client
+	// Events is the websocket event source of the node at RPCAddress
+	Events      *eventSource
+	LightClient *lightClient
+}
+
+// Chains is the chain registry by chain id
+var Chains = map[string]*Chain{}
+
+// DefaultChain serves the requests that do not select a chain
+var DefaultChain *Chain
+
+// registryMtx guards Chains, DefaultChain and LoadedConfig, which a config reload replaces while requests are served
+var registryMtx sync.RWMutex
+
+// chainKey is the context key of the chain serving a request
+type chainKey struct{}
+
+// newChain builds the registry entry of a configured chain
+func newChain(conf ChainConfig) (*Chain, error) {
+	if conf.ChainID == "" || conf.GRPCAddress == "" || conf.RPCAddress == "" || conf.Bech32Prefix == "" {
+		return nil, fmt.Errorf("chain %q needs chain_id, grpc_address, rpc_address and bech32_prefix", conf.ChainID)
+	}
+	if conf.Encoding == "" {
+		conf.Encoding = DEFAULT_ENCODING
+	}
+	makeEncodingConfig, ok := ENCODINGS[conf.Encoding]
+	if !ok {
+		return nil, fmt.Errorf("chain %s has an unknown encoding %q", conf.ChainID, conf.Encoding)
+	}
+	encodingConfig := makeEncodingConfig()
+	if conf.Quorum < 0 || conf.Quorum > len(conf.QuorumUpstreams)+1 {
+		return nil, fmt.Errorf("chain %s has a quorum of %d for %d upstreams", conf.ChainID, conf.Quorum, len(conf.QuorumUpstreams)+1)
+	}
+	lc, err := newLightClient(conf.ChainID, conf.LightClient)
+	if err != nil {
+		return nil, fmt.Errorf("chain %s: %w", conf.ChainID, err)
+	}
+	return &Chain{
+		ChainConfig: conf,
+		Context: client.Context{}.WithChainID(conf.ChainID).
+			WithCodec(encodingConfig.Marshaler).
+			WithInterfaceRegistry(encodingConfig.InterfaceRegistry).
+			WithTxConfig(encodingConfig.TxConfig).
+			WithLegacyAmino(encodingConfig.Amino).
+			WithNodeURI(conf.RPCAddress),
+		RPC:         tmrpc.New(conf.RPCAddress),
+		Events:      newEventSource(conf.ChainID, conf.RPCAddress),
+		LightClient: lc,
+	}, nil
+}
+
+// initChains replaces the built-in chain with the configured registry. Without configured chains the built-in
+// Osmosis chain keeps serving every request, with the top level light client config.
+func initChains(config *Config) error {
+	chains, defaultChain, err := buildChains(config, Chains)
+	if err != nil {
+		return err
+	}
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+	Chains, DefaultChain = chains, defaultChain
+	return nil
+}
+
+// buildChains builds the chain registry of config and its default chain. A chain of previous whose config is
+// unchanged is kept as it is, with its event source and verified light blocks.
+func buildChains(config *Config, previous map[string]*Chain) (map[string]*Chain, *Chain, error) {
+	if len(config.Chains) == 0 {
+		chain, err := builtinChain(config.LightClient, previous[CHAIN_ID])
+		if err != nil {
+			return nil, nil, err
+		}
+		return map[string]*Chain{CHAIN_ID: chain}, chain, nil
+	}
+	chains := make(map[string]*Chain, len(config.Chains))
+	for _, conf := range config.Chains {
+		if _, ok := chains[conf.ChainID]; ok {
+			return nil, nil, fmt.Errorf("chain %s is configured twice", conf.ChainID)
+		}
+		if conf.Encoding == "" {
+			conf.Encoding = DEFAULT_ENCODING
+		}
+		if chain, ok := previous[conf.ChainID]; ok && reflect.DeepEqual(chain.ChainConfig, conf) {
+			chains[conf.ChainID] = chain
+			continue
+		}
+		chain, err := newChain(conf)
+		if err != nil {
+			return nil, nil, err
+		}
+		chains[conf.ChainID] = chain
+	}
+	defaultChain := config.DefaultChain
+	if defaultChain == "" {
+		defaultChain = config.Chains[0].ChainID
+	}
+	if _, ok := chains[defaultChain]; !ok {
+		return nil, nil, fmt.Errorf("default chain %s is not configured", defaultChain)
+	}
+	return chains, chains[defaultChain], nil
+}
+
+// builtinChain returns the built-in Osmosis chain with the light client of conf, keeping previous when it is the
+// built-in chain already. Its event source is shared with previous.
+func builtinChain(conf LightClientConfig, previous *Chain) (*Chain, error) {
+	if previous == nil || previous.GRPCAddress != GRPC_SERVER_ADDRESS || previous.RPCAddress != RPC_URL {
+		previous = newBuiltinChain()
+	}
+	if previous.ChainConfig.LightClient == conf && (previous.LightClient != nil || conf.TrustedHeight == 0) {
+		return previous, nil
+	}
+	lc, err := newLightClient(CHAIN_ID, conf)
+	if err != nil {
+		return nil, err
+	}
+	chain := *previous
+	chain.ChainConfig.LightClient, chain.LightClient = conf, lc
+	return &chain, nil
+}
+
+// lookupChain returns the chain of the registry with chainID, the default chain when chainID is empty
+func lookupChain(chainID string) (*Chain, bool) {
+	registryMtx.RLock()
+	defer registryMtx.RUnlock()
+	if chainID == "" {
+		return DefaultChain, true
+	}
+	chain, ok := Chains[chainID]
+	return chain, ok
+}
+
+// chainList returns the chains of the registry by chain id
+func chainList() []*Chain {
+	registryMtx.RLock()
+	defer registryMtx.RUnlock()
+	chains := make([]*Chain, 0, len(Chains))
+	for _, chain := range Chains {
+		chains = append(chains, chain)
+	}
+	sort.Slice(chains, func(i, j int) bool { return chains[i].ChainID < chains[j].ChainID })
+	return chains
+}
+
+// chainFromContext returns the chain serving a request, the default chain when none was selected
+func chainFromContext(ctx context.Context) *Chain {
+	if chain, ok := ctx.Value(chainKey{}).(*Chain); ok {
+		return chain
+	}
+	chain, _ := lookupChain("")
+	return chain
+}
+
+// chainInterceptor routes a request to the chain selected by its chain_id field, or else by the x-chain-id header
+func chainInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	chainID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(CHAIN_ID_HEADER); len(values) > 0 {
+			chainID = values[0]
+		}
+	}
+	if r, ok := req.(interface{ GetChainId() string }); ok && r.GetChainId() != "" {
+		chainID = r.GetChainId()
+	}
+	chain, ok := lookupChain(chainID)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown chain %q", chainID)
+	}
+	return handler(context.WithValue(ctx, chainKey{}, chain), req)
+}
+
+// accAddress returns the bech32 account address of the chain
+func (c *Chain) accAddress(bz []byte) string {
+	return c.bech32(c.Bech32Prefix, bz)
+}
+
+// valAddress returns the bech32 validator operator address of the chain
+func (c *Chain) valAddress(bz []byte) string {
+	return c.bech32(c.valPrefix(), bz)
+}
+
+// consAddress returns the bech32 consensus address of the chain
+func (c *Chain) consAddress(bz []byte) string {
+	return c.bech32(c.consPrefix(), bz)
+}
+
+// valPrefix is the bech32 prefix of validator operator addresses
+func (c *Chain) valPrefix() string {
+	return c.Bech32Prefix + sdk.PrefixValidator + sdk.PrefixOperator
+}
+
+// consPrefix is the bech32 prefix of consensus addresses
+func (c *Chain) consPrefix() string {
+	return c.Bech32Prefix + sdk.PrefixValidator + sdk.PrefixConsensus
+}
+
+// bech32 encodes bz with the prefix hrp, panicking like the sdk address types on data bech32 cannot hold
+func (c *Chain) bech32(hrp string, bz []byte) string {
+	s, err := bech32.ConvertAndEncode(hrp, bz)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// fromBech32 decodes a bech32 address of the chain with the prefix hrp
+func (c *Chain) fromBech32(hrp, address string) ([]byte, error) {
+	prefix, bz, err := bech32.DecodeAndConvert(address)
+	if err != nil {
+		return nil, err
+	}
+	if prefix != hrp {
+		return nil, fmt.Errorf("invalid bech32 prefix %s, expected %s", prefix, hrp)
+	}
+	return bz, nil
+}
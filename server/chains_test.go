@@ -0,0 +1,166 @@
+// This file contains tests for the chain registry and the routing of requests to a chain.
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	types "grpc_server4/proto/generated"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// testChainConfigs are a chain registry of two chains with different bech32 prefixes
+var testChainConfigs = []ChainConfig{
+	{ChainID: "osmosis-1", GRPCAddress: "osmosis:9090", RPCAddress: "http://osmosis:26657", Bech32Prefix: "osmo"},
+	{ChainID: "cosmoshub-4", GRPCAddress: "cosmoshub:9090", RPCAddress: "http://cosmoshub:26657", Bech32Prefix: "cosmos"},
+}
+
+// TestInitChains tests that the configured chains replace the built-in one and that the default chain is selected
+func TestInitChains(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+	defer InitCcontext()
+
+	if err := initChains(&Config{Chains: testChainConfigs, DefaultChain: "cosmoshub-4"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(Chains) != 2 || DefaultChain != Chains["cosmoshub-4"] {
+		t.Fatalf("expected 2 chains with cosmoshub-4 as default, got %d and %s", len(Chains), DefaultChain.ChainID)
+	}
+	if DefaultChain.Encoding != DEFAULT_ENCODING || DefaultChain.Context.TxConfig == nil {
+		t.Errorf("expected the default encoding, got %q", DefaultChain.Encoding)
+	}
+
+	if err := initChains(&Config{Chains: testChainConfigs}); err != nil {
+		t.Fatal(err)
+	}
+	if DefaultChain.ChainID != "osmosis-1" {
+		t.Errorf("expected the first chain as default, got %s", DefaultChain.ChainID)
+	}
+
+	invalid := map[string]*Config{
+		"unknown default":  {Chains: testChainConfigs, DefaultChain: "juno-1"},
+		"duplicate":        {Chains: []ChainConfig{testChainConfigs[0], testChainConfigs[0]}},
+		"missing prefix":   {Chains: []ChainConfig{{ChainID: "juno-1", GRPCAddress: "juno:9090", RPCAddress: "http://juno:26657"}}},
+		"unknown encoding": {Chains: []ChainConfig{{ChainID: "juno-1", GRPCAddress: "juno:9090", RPCAddress: "http://juno:26657", Bech32Prefix: "juno", Encoding: "juno"}}},
+	}
+	for name, config := range invalid {
+		if err := initChains(config); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// TestCosmosEncoding tests that a chain with the cosmos encoding decodes the standard txs but not the Osmosis types
+func TestCosmosEncoding(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+	defer InitCcontext()
+
+	configs := []ChainConfig{testChainConfigs[0], testChainConfigs[1]}
+	configs[1].Encoding = "cosmos"
+	if err := initChains(&Config{Chains: configs}); err != nil {
+		t.Fatal(err)
+	}
+	chain := Chains["cosmoshub-4"]
+	from := sdk.AccAddress([]byte("from________________"))
+	ans := chain.decodeTx(buildTestTx(t, chain, from))
+	if ans.DecodeError != "" || len(ans.Signers) != 1 || ans.Signers[0] != chain.accAddress(from) {
+		t.Fatalf("expected the MsgSend signed by %s, got %v %q", chain.accAddress(from), ans.Signers, ans.DecodeError)
+	}
+	if !strings.HasPrefix(ans.Signers[0], "cosmos1") || ans.Fee == nil || ans.Fee.Payer != ans.Signers[0] || ans.Fee.Amount[0].Amount != "25" {
+		t.Errorf("expected the fee of 25 paid by the cosmos1 signer, got %v", ans.Fee)
+	}
+	if !strings.Contains(ans.Json, chain.accAddress(from)) {
+		t.Errorf("expected the json of the tx, got %q", ans.Json)
+	}
+	if _, err := chain.Context.InterfaceRegistry.Resolve("/osmosis.gamm.v1beta1.MsgSwapExactAmountIn"); err == nil {
+		t.Error("expected the Osmosis messages unknown to the cosmos encoding")
+	}
+}
+
+// TestChainInterceptor tests that a request is routed by its chain_id field first, then by the x-chain-id header
+func TestChainInterceptor(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+	defer InitCcontext()
+
+	if err := initChains(&Config{Chains: testChainConfigs}); err != nil {
+		t.Fatal(err)
+	}
+	var served *Chain
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		served = chainFromContext(ctx)
+		return nil, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/GrpcQueryService/GetSyncing"}
+	header := metadata.NewIncomingContext(context.Background(), metadata.Pairs(CHAIN_ID_HEADER, "cosmoshub-4"))
+
+	cases := []struct {
+		name    string
+		ctx     context.Context
+		req     *types.GetSyncingRequest
+		chainID string
+	}{
+		{"default", context.Background(), &types.GetSyncingRequest{}, "osmosis-1"},
+		{"header", header, &types.GetSyncingRequest{}, "cosmoshub-4"},
+		{"field", context.Background(), &types.GetSyncingRequest{ChainId: "cosmoshub-4"}, "cosmoshub-4"},
+		{"field over header", header, &types.GetSyncingRequest{ChainId: "osmosis-1"}, "osmosis-1"},
+	}
+	for _, c := range cases {
+		served = nil
+		if _, err := chainInterceptor(c.ctx, c.req, info, handler); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if served == nil || served.ChainID != c.chainID {
+			t.Errorf("%s: expected chain %s, got %v", c.name, c.chainID, served)
+		}
+	}
+
+	_, err := chainInterceptor(context.Background(), &types.GetSyncingRequest{ChainId: "juno-1"}, info, handler)
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown chain, got %v", err)
+	}
+}
+
+// TestChainAddresses tests that addresses are rendered and parsed with the bech32 prefixes of the chain
+func TestChainAddresses(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	chain, err := newChain(testChainConfigs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	bz := make([]byte, 20)
+	for i := range bz {
+		bz[i] = byte(i)
+	}
+	consAddr := chain.consAddress(bz)
+	if consAddr[:len("cosmosvalcons1")] != "cosmosvalcons1" {
+		t.Errorf("expected a cosmosvalcons address, got %s", consAddr)
+	}
+	if addr := chain.accAddress(bz); addr[:len("cosmos1")] != "cosmos1" {
+		t.Errorf("expected a cosmos address, got %s", addr)
+	}
+	if addr := chain.valAddress(bz); addr[:len("cosmosvaloper1")] != "cosmosvaloper1" {
+		t.Errorf("expected a cosmosvaloper address, got %s", addr)
+	}
+
+	address, err := chain.consensusAddress(consAddr, nil)
+	if err != nil || address != consAddr {
+		t.Errorf("consensusAddress(%q) = %q, %v", consAddr, address, err)
+	}
+	if address, err := chain.consensusAddress("000102030405060708090A0B0C0D0E0F10111213", nil); err != nil || address != consAddr {
+		t.Errorf("expected the hex address to resolve to %s, got %q, %v", consAddr, address, err)
+	}
+	if _, err := chain.consensusAddress(DefaultChain.consAddress(bz), nil); err == nil {
+		t.Error("expected an osmovalcons address to be rejected by the cosmoshub chain")
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"grpc_server4/grpczstd"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+// DEFAULT_MAX_MSG_SIZE bounds the messages of the server and of the upstreams when the config does not. Blocks
+// with many txs exceed the 4 MiB gRPC default, more so with their decoded txs.
+const DEFAULT_MAX_MSG_SIZE = 64 << 20
+
+// COMPRESSIONS are the compressions the server accepts and answers with, a client picks one per call with the
+// grpc-encoding header
+var COMPRESSIONS = []string{gzip.Name, grpczstd.Name}
+
+// GRPCConfig sizes the messages of the server and of the upstream calls, and their compression
+type GRPCConfig struct {
+	// MaxRecvMsgSize and MaxSendMsgSize bound the requests and responses of the server, DEFAULT_MAX_MSG_SIZE
+	// when zero. They are applied on restart.
+	MaxRecvMsgSize int `yaml:"max_recv_msg_size"`
+	MaxSendMsgSize int `yaml:"max_send_msg_size"`
+	// UpstreamMaxMsgSize bounds the responses of the upstreams, DEFAULT_MAX_MSG_SIZE when zero
+	UpstreamMaxMsgSize int `yaml:"upstream_max_msg_size"`
+	// UpstreamCompression is the compression of the calls to the upstreams, gzip or zstd, none when empty.
+	// The upstream node must have registered it.
+	UpstreamCompression string `yaml:"upstream_compression"`
+}
+
+// validate checks the message sizes and the upstream compression
+func (c GRPCConfig) validate() error {
+	if c.MaxRecvMsgSize < 0 || c.MaxSendMsgSize < 0 || c.UpstreamMaxMsgSize < 0 {
+		return fmt.Errorf("negative grpc message size")
+	}
+	if c.UpstreamCompression != "" && encoding.GetCompressor(c.UpstreamCompression) == nil {
+		return fmt.Errorf("unknown grpc upstream compression %q, expected one of %v", c.UpstreamCompression, COMPRESSIONS)
+	}
+	return nil
+}
+
+// orDefault returns size, or DEFAULT_MAX_MSG_SIZE when it is zero
+func orDefault(size int) int {
+	if size == 0 {
+		return DEFAULT_MAX_MSG_SIZE
+	}
+	return size
+}
+
+// serverOptions returns the message size options of the server
+func (c GRPCConfig) serverOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(orDefault(c.MaxRecvMsgSize)),
+		grpc.MaxSendMsgSize(orDefault(c.MaxSendMsgSize)),
+	}
+}
+
+// upstreamCallOptions returns the dial option sizing and compressing the calls to the upstreams, from the
+// running config
+func upstreamCallOptions() grpc.DialOption {
+	c := runningConfig().GRPC
+	size := orDefault(c.UpstreamMaxMsgSize)
+	opts := []grpc.CallOption{grpc.MaxCallRecvMsgSize(size), grpc.MaxCallSendMsgSize(size)}
+	if c.UpstreamCompression != "" {
+		opts = append(opts, grpc.UseCompressor(c.UpstreamCompression))
+	}
+	return grpc.WithDefaultCallOptions(opts...)
+}
@@ -0,0 +1,143 @@
+// This file contains tests for the message sizes and compressions of the server, and the benchmark of the
+// GetBlockByHeight payload sizes.
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"grpc_server4/grpczstd"
+	types "grpc_server4/proto/generated"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// testBlockResponse returns a GetBlockByHeight response of a block of n distinct txs with their decoded txs
+func testBlockResponse(tb testing.TB, n int) *types.GetBlockByHeightResponse {
+	txs := make([][]byte, n)
+	for i := range txs {
+		txs[i] = buildTestTx(tb, DefaultChain, sdk.AccAddress([]byte(fmt.Sprintf("from%016d", i))))
+	}
+	return &types.GetBlockByHeightResponse{
+		BlockId:    &tmproto.BlockID{Hash: bytes.Repeat([]byte{1}, 32)},
+		Block:      &tmproto.Block{Header: tmproto.Header{ChainID: CHAIN_ID, Height: 8700000}, Data: tmproto.Data{Txs: txs}},
+		DecodedTxs: DefaultChain.decodeTxs(txs),
+	}
+}
+
+// bigBlockService serves a GetBlockByHeight response larger than the 4 MiB gRPC default
+type bigBlockService struct {
+	types.UnimplementedGrpcQueryServiceServer
+	resp *types.GetBlockByHeightResponse
+}
+
+func (s *bigBlockService) GetBlockByHeight(ctx context.Context, req *types.GetBlockByHeightRequest) (*types.GetBlockByHeightResponse, error) {
+	return s.resp, nil
+}
+
+// TestLargeMessages tests that a block over 4 MiB goes through with the configured sizes in every compression,
+// and fails over the size the client allows
+func TestLargeMessages(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	resp := testBlockResponse(t, 6000)
+	size := proto.Size(resp)
+	if size <= 4<<20 {
+		t.Fatalf("expected a response over 4 MiB, got %d bytes", size)
+	}
+	listener := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(GRPCConfig{}.serverOptions()...)
+	types.RegisterGrpcQueryServiceServer(s, &bigBlockService{resp: resp})
+	go s.Serve(listener)
+	defer s.Stop()
+	conn, err := grpc.Dial("bufconn", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c := types.NewGrpcQueryServiceClient(conn)
+
+	for _, compression := range append([]string{""}, COMPRESSIONS...) {
+		opts := []grpc.CallOption{grpc.MaxCallRecvMsgSize(DEFAULT_MAX_MSG_SIZE)}
+		if compression != "" {
+			opts = append(opts, grpc.UseCompressor(compression))
+		}
+		got, err := c.GetBlockByHeight(context.Background(), &types.GetBlockByHeightRequest{Height: 8700000}, opts...)
+		if err != nil {
+			t.Fatalf("%q: %v", compression, err)
+		}
+		if len(got.Block.Data.Txs) != len(resp.Block.Data.Txs) || len(got.DecodedTxs) != len(resp.DecodedTxs) {
+			t.Errorf("%q: expected %d txs, got %d", compression, len(resp.Block.Data.Txs), len(got.Block.Data.Txs))
+		}
+	}
+	_, err = c.GetBlockByHeight(context.Background(), &types.GetBlockByHeightRequest{Height: 8700000})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected ResourceExhausted over the 4 MiB client default, got %v", err)
+	}
+}
+
+// TestGRPCConfigValidate tests that negative sizes and unknown compressions are rejected
+func TestGRPCConfigValidate(t *testing.T) {
+	valid := []GRPCConfig{{}, {MaxRecvMsgSize: 1 << 20, UpstreamCompression: gzip.Name}, {UpstreamCompression: grpczstd.Name}}
+	for _, c := range valid {
+		if err := c.validate(); err != nil {
+			t.Errorf("%+v: %v", c, err)
+		}
+	}
+	invalid := []GRPCConfig{{MaxSendMsgSize: -1}, {UpstreamCompression: "brotli"}}
+	for _, c := range invalid {
+		if err := c.validate(); err == nil {
+			t.Errorf("%+v: expected an error", c)
+		}
+	}
+}
+
+// BenchmarkGetBlockByHeightPayload compares the size of a GetBlockByHeight response of a block of 2000 txs on
+// the wire in every compression, reported as wire-bytes and the ratio to the uncompressed size
+func BenchmarkGetBlockByHeightPayload(b *testing.B) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	bz, err := proto.Marshal(testBlockResponse(b, 2000))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("identity", func(b *testing.B) {
+		b.ReportMetric(float64(len(bz)), "wire-bytes")
+		b.ReportMetric(1, "ratio")
+	})
+	for _, name := range COMPRESSIONS {
+		c := encoding.GetCompressor(name)
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(bz)))
+			var buf bytes.Buffer
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+				w, err := c.Compress(&buf)
+				if err != nil {
+					b.Fatal(err)
+				}
+				w.Write(bz)
+				if err := w.Close(); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(buf.Len()), "wire-bytes")
+			b.ReportMetric(float64(buf.Len())/float64(len(bz)), "ratio")
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"io/ioutil"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// CONFIG_FILE is the path of the server configuration, relative to the server directory it is started from
+const CONFIG_FILE = "config/config.yaml"
+
+// Config is the server configuration read from CONFIG_FILE
+type Config struct {
+	// Chains is the chain registry, the built-in Osmosis chain serves every request when it is empty
+	Chains []ChainConfig `yaml:"chains"`
+	// DefaultChain serves the requests that do not select a chain, the first of Chains by default
+	DefaultChain string `yaml:"default_chain"`
+	// LightClient is the light client of the built-in Osmosis chain
+	LightClient LightClientConfig `yaml:"light_client"`
+	// LogLevel is debug, info, warn or error, DEFAULT_LOG_LEVEL when empty
+	LogLevel string      `yaml:"log_level"`
+	Admin    AdminConfig `yaml:"admin"`
+	GRPC     GRPCConfig  `yaml:"grpc"`
+	// Listeners serve GrpcQueryService and the HTTP handlers on each of their ports, DEFAULT_LISTEN when empty
+	Listeners []ListenerConfig `yaml:"listeners"`
+	GRPCWeb   GRPCWebConfig    `yaml:"grpc_web"`
+}
+
+// AdminConfig is the listener of the AdminService
+type AdminConfig struct {
+	// Listen is the address the AdminService listens on like a ListenerConfig address, it is not served when empty
+	Listen string `yaml:"listen"`
+	// SocketMode is the octal file mode of a unix socket, DEFAULT_SOCKET_MODE when empty
+	SocketMode string `yaml:"socket_mode"`
+}
+
+// LightClientConfig is the trust point of the light client. The light client is disabled when no trusted header is set.
+type LightClientConfig struct {
+	// TrustedHeight and TrustedHash identify a header obtained from a source trusted out of band
+	TrustedHeight int64  `yaml:"trusted_height"`
+	TrustedHash   string `yaml:"trusted_hash"`
+	// TrustingPeriod is how long a verified header can be trusted, it must be shorter than the unbonding period
+	TrustingPeriod time.Duration `yaml:"trusting_period"`
+}
+
+// loadConfig reads the server configuration from the yaml file at path
+func loadConfig(path string) (*Config, error) {
+	bz, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	if err := yaml.Unmarshal(bz, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
@@ -0,0 +1,15 @@
+// This file contains tests for the server configuration.
+package main
+
+import "testing"
+
+// TestLoadConfig tests that the shipped configuration loads and leaves the light client disabled
+func TestLoadConfig(t *testing.T) {
+	config, err := loadConfig(CONFIG_FILE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, err := newLightClient(CHAIN_ID, config.LightClient); c != nil || err != nil {
+		t.Errorf("expected the light client to be disabled, got %v %v", c, err)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"grpc_server4/tmrpc"
+	"sync"
+
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// EVENT_QUERIES are the tendermint events the server subscribes to on the websocket of every chain. Only the new
+// blocks have a consumer, the latest block; tmrpc.WSClient also takes the Tx and ValidatorSetUpdates queries.
+var EVENT_QUERIES = []string{
+	tmtypes.EventQueryNewBlock.String(),
+}
+
+// eventSource is the push event source of a chain: the new blocks of its node, read from a websocket subscription.
+// It keeps the latest block while connected.
+type eventSource struct {
+	ws *tmrpc.WSClient
+	// cancel stops the run started by start
+	cancel context.CancelFunc
+
+	mtx       sync.RWMutex
+	connected bool
+	blockID   *tmproto.BlockID
+	block     *tmproto.Block
+}
+
+// newEventSource returns the event source of the node at rpcAddress, not reading events until run
+func newEventSource(chainID, rpcAddress string) *eventSource {
+	e := &eventSource{
+		ws: tmrpc.NewWSClient(rpcAddress, EVENT_QUERIES...),
+	}
+	e.ws.OnConnect = e.setConnected
+	e.ws.OnError = func(err error) {
+		logf("warn", "%s event source: %v", chainID, err)
+	}
+	return e
+}
+
+// run reads the events until ctx is done
+func (e *eventSource) run(ctx context.Context) {
+	e.ws.Run(ctx, e.handle)
+}
+
+// setConnected records the state of the subscription, dropping the latest block when it is lost since no newer
+// block would replace it
+func (e *eventSource) setConnected(connected bool) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	e.connected = connected
+	if !connected {
+		e.blockID, e.block = nil, nil
+	}
+}
+
+// isConnected reports whether the subscription is connected
+func (e *eventSource) isConnected() bool {
+	e.mtx.RLock()
+	defer e.mtx.RUnlock()
+	return e.connected
+}
+
+// flush drops the latest block until the next one is pushed, returning the number of blocks dropped
+func (e *eventSource) flush() int64 {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	if e.block == nil {
+		return 0
+	}
+	e.blockID, e.block = nil, nil
+	return 1
+}
+
+// handle keeps the block of a NewBlock event
+func (e *eventSource) handle(event tmrpc.Event) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	if newBlock, ok := event.Data.(tmtypes.EventDataNewBlock); ok && newBlock.Block != nil {
+		if block, err := newBlock.Block.ToProto(); err == nil {
+			parts := newBlock.Block.MakePartSet(tmtypes.BlockPartSizeBytes)
+			blockID := &tmtypes.BlockID{Hash: newBlock.Block.Hash(), PartSetHeader: parts.Header()}
+			pb := blockID.ToProto()
+			e.blockID, e.block = &pb, block
+		}
+	}
+}
+
+// latest returns the latest block pushed by the node, or nil when the subscription is not connected
+func (e *eventSource) latest() (*tmproto.BlockID, *tmproto.Block) {
+	e.mtx.RLock()
+	defer e.mtx.RUnlock()
+	return e.blockID, e.block
+}
+
+// start runs the event source until ctx is done or stop is called, it does nothing when already started
+func (e *eventSource) start(ctx context.Context) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	if e.cancel != nil {
+		return
+	}
+	ctx, e.cancel = context.WithCancel(ctx)
+	go e.run(ctx)
+}
+
+// stop ends the run started by start
+func (e *eventSource) stop() {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	if e.cancel != nil {
+		e.cancel()
+	}
+}
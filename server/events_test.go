@@ -0,0 +1,66 @@
+// This file contains tests for the websocket event source of a chain.
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	types "grpc_server4/proto/generated"
+	"grpc_server4/tmrpc"
+
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// TestEventSource tests that a NewBlock event becomes the latest block
+func TestEventSource(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	chain := newTestChain(t, 4, 3)
+	block, err := tmtypes.BlockFromProto(chain.blocks[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := newEventSource(TEST_CHAIN_ID, RPC_URL)
+	if _, b := e.latest(); b != nil {
+		t.Fatal("expected no latest block before the first event")
+	}
+	e.handle(tmrpc.Event{Query: EVENT_QUERIES[0], Data: tmtypes.EventDataNewBlock{Block: block}})
+
+	blockID, b := e.latest()
+	if b == nil || b.Header.Height != 2 {
+		t.Fatalf("expected the block at height 2, got %v", b)
+	}
+	if !bytes.Equal(blockID.Hash, chain.ids[2].Hash) || !bytes.Equal(blockID.PartSetHeader.Hash, chain.ids[2].PartSetHeader.Hash) {
+		t.Errorf("expected the block id %X, got %X", chain.ids[2].Hash, blockID.Hash)
+	}
+
+	e.setConnected(false)
+	if _, b := e.latest(); b != nil {
+		t.Error("expected the latest block to be dropped when the subscription is lost")
+	}
+}
+
+// TestGetLatestBlockPushed tests that GetLatestBlock serves the block pushed by the event source
+func TestGetLatestBlockPushed(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+	defer InitCcontext()
+
+	chain := newTestChain(t, 4, 3)
+	block, err := tmtypes.BlockFromProto(chain.blocks[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	DefaultChain.Events.handle(tmrpc.Event{Query: EVENT_QUERIES[0], Data: tmtypes.EventDataNewBlock{Block: block}})
+
+	s := server{}
+	ans, err := s.GetLatestBlock(context.Background(), &types.GetLatestBlockRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ans.Block.Header.Height != 3 || !bytes.Equal(ans.BlockId.Hash, chain.ids[3].Hash) {
+		t.Errorf("expected the pushed block at height 3, got %d", ans.Block.Header.Height)
+	}
+}
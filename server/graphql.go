@@ -0,0 +1,864 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	types "grpc_server4/proto/generated"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	graphql "github.com/graph-gophers/graphql-go"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// MAX_QUERY_COST bounds the cost of a GraphQL query: one per upstream call, a block or tx lookup or a page of a
+// validator-set or of the staking validators, and one per block whose txs are decoded. The lookups shared by
+// several fields are only counted once, and the fields exceeding it resolve to null with an error. The schema has
+// no cycles, so aliases are the only way to widen a query.
+const MAX_QUERY_COST = 100
+
+// MAX_PRIORITY_ROUNDS bounds the blocks the proposer priorities of a validator-set are advanced over, the
+// validator-set of a block further from the one read for its validators hash is read at its height
+const MAX_PRIORITY_ROUNDS = 100
+
+// MAX_QUERY_BLOCKS bounds the heights of a blocks query
+const MAX_QUERY_BLOCKS = 50
+
+// MAX_QUERY_BODY bounds the body of a POSTed GraphQL query
+const MAX_QUERY_BODY = 1 << 20
+
+// GRAPHQL_SCHEMA is the schema served on /graphql. The chainId arguments select the chain of the registry like
+// the chain_id fields of GrpcQueryService, the x-chain-id header or the default chain when they are omitted.
+const GRAPHQL_SCHEMA = `
+	schema {
+		query: Query
+	}
+
+	# 64-bit integer, written as a string like tendermint does and read from a string or a number
+	scalar Int64
+
+	type Query {
+		# the block at height, the latest one when height is omitted
+		block(height: Int64, chainId: String): Block
+		# the blocks at heights, in their order
+		blocks(heights: [Int64!]!, chainId: String): [Block!]!
+		# the complete validator-set at height, the latest one when height is omitted
+		validatorSet(height: Int64, chainId: String): ValidatorSet
+		tx(hash: String!, chainId: String): TxResult
+	}
+
+	type Block {
+		chainId: String!
+		height: Int64!
+		hash: String!
+		time: String!
+		txCount: Int!
+		# the valcons bech32 address of the proposer
+		proposerAddress: String!
+		proposer: Validator
+		validatorSet: ValidatorSet!
+		txs: [Tx!]!
+	}
+
+	type ValidatorSet {
+		height: Int64!
+		totalVotingPower: Int64!
+		validators: [Validator!]!
+	}
+
+	type Validator {
+		# the valcons bech32 address
+		address: String!
+		pubKeyBase64: String!
+		votingPower: Int64!
+		proposerPriority: Int64!
+		# the staking module fields are null when no staking validator has the consensus key, they are read once
+		# per query from the latest staking state
+		operatorAddress: String
+		moniker: String
+		jailed: Boolean
+		status: String
+	}
+
+	type Tx {
+		hash: String!
+		messageTypes: [String!]!
+		memo: String!
+		signers: [String!]!
+		json: String!
+		# set instead of the fields above when the tx could not be decoded
+		decodeError: String
+	}
+
+	type TxResult {
+		hash: String!
+		height: Int64!
+		code: Int!
+		codespace: String!
+		gasWanted: Int64!
+		gasUsed: Int64!
+		rawLog: String!
+		timestamp: String!
+		tx: Tx
+	}
+`
+
+// Int64 is the Int64 scalar of the schema
+type Int64 int64
+
+// ImplementsGraphQLType implements the graphql-go custom scalar
+func (Int64) ImplementsGraphQLType(name string) bool {
+	return name == "Int64"
+}
+
+// UnmarshalGraphQL reads an Int64 from a literal, or a variable decoded from JSON
+func (n *Int64) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case int32:
+		*n = Int64(input)
+	case int64:
+		*n = Int64(input)
+	case float64:
+		if input != float64(int64(input)) {
+			return fmt.Errorf("%v is not an integer", input)
+		}
+		*n = Int64(input)
+	case string:
+		v, err := strconv.ParseInt(input, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid Int64 %q", input)
+		}
+		*n = Int64(v)
+	default:
+		return fmt.Errorf("invalid Int64 %v", input)
+	}
+	return nil
+}
+
+// MarshalJSON writes the Int64 as a string
+func (n Int64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatInt(int64(n), 10))
+}
+
+// graphqlQuery is the state of a running GraphQL query: the chain selected by its header, its cost so far and
+// the loaders sharing the upstream calls between fields. sets holds the validator-sets by validators hash, heights
+// the ones of every height with their proposer priorities, and operators the staking operators of every chain.
+type graphqlQuery struct {
+	chainID   string
+	cost      int64
+	blocks    loader
+	sets      loader
+	heights   loader
+	operators loader
+	txs       loader
+	// lowest is the lowest height of the blocks loaded by validators hash
+	mtx    sync.Mutex
+	lowest map[string]int64
+}
+
+// see records a block loaded by the query, the validator-set of its validators hash is read at the lowest height
+// seen so the priorities of the higher blocks are advanced from it
+func (q *graphqlQuery) see(chain *Chain, header tmproto.Header) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	if q.lowest == nil {
+		q.lowest = make(map[string]int64)
+	}
+	key := fmt.Sprintf("%s/%X", chain.ChainID, header.ValidatorsHash)
+	if lowest, ok := q.lowest[key]; !ok || header.Height < lowest {
+		q.lowest[key] = header.Height
+	}
+}
+
+// lowestHeight returns the lowest height seen of the validators hash of header
+func (q *graphqlQuery) lowestHeight(chain *Chain, header tmproto.Header) int64 {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	if lowest, ok := q.lowest[fmt.Sprintf("%s/%X", chain.ChainID, header.ValidatorsHash)]; ok && lowest < header.Height {
+		return lowest
+	}
+	return header.Height
+}
+
+// graphqlQueryKey is the context key of the running GraphQL query
+type graphqlQueryKey struct{}
+
+// queryFromContext returns the running GraphQL query
+func queryFromContext(ctx context.Context) *graphqlQuery {
+	if q, ok := ctx.Value(graphqlQueryKey{}).(*graphqlQuery); ok {
+		return q
+	}
+	return &graphqlQuery{}
+}
+
+// charge adds one to the cost of the query, failing once it exceeds MAX_QUERY_COST
+func (q *graphqlQuery) charge() error {
+	if atomic.AddInt64(&q.cost, 1) > MAX_QUERY_COST {
+		return fmt.Errorf("query cost exceeds %d", MAX_QUERY_COST)
+	}
+	return nil
+}
+
+// loader shares the loads of a query by key, dataloader style: the first load of a key calls fetch and every
+// other load of the key, concurrent or later, waits for its result. The fields of a query asking for the same
+// block or validator-set make one call.
+type loader struct {
+	mtx   sync.Mutex
+	calls map[string]*loaderCall
+}
+
+// loaderCall is a load in flight or done
+type loaderCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// load returns the value of key, calling fetch only on the first load of key
+func (l *loader) load(key string, fetch func() (interface{}, error)) (interface{}, error) {
+	l.mtx.Lock()
+	if l.calls == nil {
+		l.calls = make(map[string]*loaderCall)
+	}
+	call, ok := l.calls[key]
+	if !ok {
+		call = &loaderCall{done: make(chan struct{})}
+		l.calls[key] = call
+	}
+	l.mtx.Unlock()
+	if ok {
+		<-call.done
+		return call.value, call.err
+	}
+	call.value, call.err = fetch()
+	close(call.done)
+	return call.value, call.err
+}
+
+// chargedService charges the running query for the validator-set calls of a tmservice
+type chargedService struct {
+	tmservice.ServiceClient
+	query *graphqlQuery
+}
+
+func (s *chargedService) GetLatestValidatorSet(ctx context.Context, in *tmservice.GetLatestValidatorSetRequest, opts ...grpc.CallOption) (*tmservice.GetLatestValidatorSetResponse, error) {
+	if err := s.query.charge(); err != nil {
+		return nil, err
+	}
+	return s.ServiceClient.GetLatestValidatorSet(ctx, in, opts...)
+}
+
+func (s *chargedService) GetValidatorSetByHeight(ctx context.Context, in *tmservice.GetValidatorSetByHeightRequest, opts ...grpc.CallOption) (*tmservice.GetValidatorSetByHeightResponse, error) {
+	if err := s.query.charge(); err != nil {
+		return nil, err
+	}
+	return s.ServiceClient.GetValidatorSetByHeight(ctx, in, opts...)
+}
+
+// chargedStaking charges the running query for the validators calls of a staking module
+type chargedStaking struct {
+	stakingtypes.QueryClient
+	query *graphqlQuery
+}
+
+func (s *chargedStaking) Validators(ctx context.Context, in *stakingtypes.QueryValidatorsRequest, opts ...grpc.CallOption) (*stakingtypes.QueryValidatorsResponse, error) {
+	if err := s.query.charge(); err != nil {
+		return nil, err
+	}
+	return s.QueryClient.Validators(ctx, in, opts...)
+}
+
+// dialChain connects to the tmservice and the staking module at the gRPC address of chain
+func dialChain(chain *Chain) (tmservice.ServiceClient, stakingtypes.QueryClient, func(), error) {
+	grpcConn, err := grpc.Dial(
+		chain.GRPCAddress, // your gRPC server address.
+		grpc.WithInsecure(),
+		upstreamCallOptions(),
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return tmservice.NewServiceClient(grpcConn), stakingtypes.NewQueryClient(grpcConn), func() {
+		err := grpcConn.Close()
+		if err != nil {
+			fmt.Println("grpcConn.Close() err:", err)
+		}
+	}, nil
+}
+
+// graphqlHandler serves GraphQL queries over the GrpcQueryService handlers of service
+type graphqlHandler struct {
+	schema *graphql.Schema
+}
+
+// newGraphQLHandler returns the GraphQL handler resolving blocks and txs with service, and validator-sets and
+// staking operators from the upstreams connected to by dial
+func newGraphQLHandler(service types.GrpcQueryServiceServer, dial func(chain *Chain) (tmservice.ServiceClient, stakingtypes.QueryClient, func(), error)) *graphqlHandler {
+	schema := graphql.MustParseSchema(GRAPHQL_SCHEMA, &queryResolver{service: service, dial: dial})
+	return &graphqlHandler{schema: schema}
+}
+
+// graphqlRequest is a GraphQL query POSTed as JSON
+type graphqlRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// ServeHTTP answers a query POSTed as JSON, or passed in the query, operationName and variables params of a GET
+func (h *graphqlHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req graphqlRequest
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if variables := r.URL.Query().Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				http.Error(w, fmt.Sprintf("invalid variables: %v", err), http.StatusBadRequest)
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MAX_QUERY_BODY)).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid query: %v", err), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := context.WithValue(r.Context(), graphqlQueryKey{}, &graphqlQuery{chainID: r.Header.Get(CHAIN_ID_HEADER)})
+	resp := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	bz, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(bz)
+}
+
+// queryResolver resolves the Query type
+type queryResolver struct {
+	service types.GrpcQueryServiceServer
+	dial    func(chain *Chain) (tmservice.ServiceClient, stakingtypes.QueryClient, func(), error)
+}
+
+// graphqlChain returns the chain selected by the chainId argument, else by the header of the query, and the
+// context of the GrpcQueryService calls for it
+func graphqlChain(ctx context.Context, chainID *string) (context.Context, *Chain, error) {
+	id := queryFromContext(ctx).chainID
+	if chainID != nil && *chainID != "" {
+		id = *chainID
+	}
+	chain, ok := lookupChain(id)
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown chain %q", id)
+	}
+	return context.WithValue(ctx, chainKey{}, chain), chain, nil
+}
+
+// graphqlError returns the message of a GrpcQueryService error, without its gRPC code
+func graphqlError(err error) error {
+	if st, ok := status.FromError(err); ok {
+		return fmt.Errorf("%s", st.Message())
+	}
+	return err
+}
+
+// block loads the block at height, the latest one when it is zero
+func (r *queryResolver) block(ctx context.Context, chain *Chain, height int64) (*blockResolver, error) {
+	q := queryFromContext(ctx)
+	value, err := q.blocks.load(fmt.Sprintf("%s/%d", chain.ChainID, height), func() (interface{}, error) {
+		if err := q.charge(); err != nil {
+			return nil, err
+		}
+		if height == 0 {
+			resp, err := r.service.GetLatestBlock(ctx, &types.GetLatestBlockRequest{ChainId: chain.ChainID})
+			if err != nil {
+				return nil, graphqlError(err)
+			}
+			q.see(chain, resp.Block.Header)
+			return &blockResolver{query: r, chain: chain, id: resp.BlockId, block: resp.Block}, nil
+		}
+		resp, err := r.service.GetBlockByHeight(ctx, &types.GetBlockByHeightRequest{Height: height, ChainId: chain.ChainID})
+		if err != nil {
+			return nil, graphqlError(err)
+		}
+		q.see(chain, resp.Block.Header)
+		return &blockResolver{query: r, chain: chain, id: resp.BlockId, block: resp.Block}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*blockResolver), nil
+}
+
+// graphqlSet is a complete validator-set read at height
+type graphqlSet struct {
+	height     int64
+	validators []*types.Validator
+	tm         *tmtypes.ValidatorSet
+}
+
+// resolver returns the validator-set at height with the proposer priorities of tm, a copy of the set advanced to it
+func (s *graphqlSet) resolver(r *queryResolver, chain *Chain, height int64, tm *tmtypes.ValidatorSet) *validatorSetResolver {
+	priorities := make([]int64, 0, len(tm.Validators))
+	for _, v := range tm.Validators {
+		priorities = append(priorities, v.ProposerPriority)
+	}
+	return &validatorSetResolver{
+		query:      r,
+		chain:      chain,
+		height:     height,
+		total:      totalVotingPower(s.validators),
+		validators: s.validators,
+		priorities: priorities,
+	}
+}
+
+// fetchSet reads the complete validator-set at height, the latest one when it is zero
+func (r *queryResolver) fetchSet(ctx context.Context, chain *Chain, height int64) (*graphqlSet, error) {
+	client, _, done, err := r.dial(chain)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	height, all, err := completeValidatorSet(ctx, &chargedService{ServiceClient: client, query: queryFromContext(ctx)}, height)
+	if err != nil {
+		return nil, graphqlError(err)
+	}
+	tm, err := chain.tmValidatorSet(all)
+	if err != nil {
+		return nil, err
+	}
+	return &graphqlSet{height: height, validators: chain.validators(all), tm: tm}, nil
+}
+
+// validatorSet loads the complete validator-set at height, the latest one when it is zero
+func (r *queryResolver) validatorSet(ctx context.Context, chain *Chain, height int64) (*validatorSetResolver, error) {
+	q := queryFromContext(ctx)
+	value, err := q.heights.load(fmt.Sprintf("%s/%d", chain.ChainID, height), func() (interface{}, error) {
+		set, err := r.fetchSet(ctx, chain, height)
+		if err != nil {
+			return nil, err
+		}
+		return set.resolver(r, chain, set.height, set.tm), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*validatorSetResolver), nil
+}
+
+// blockValidatorSet loads the validator-set signing the block with header. The validators are read once per
+// validators hash, at the lowest height of the blocks of the query signed by them, and their proposer priorities
+// advanced to the height of header from that one, as tendermint does while the set does not change. The set is read at the height of header when it is below that
+// one or more than MAX_PRIORITY_ROUNDS above it, or when the advanced priorities do not elect its proposer.
+func (r *queryResolver) blockValidatorSet(ctx context.Context, chain *Chain, header tmproto.Header) (*validatorSetResolver, error) {
+	q := queryFromContext(ctx)
+	value, err := q.heights.load(fmt.Sprintf("%s/%d", chain.ChainID, header.Height), func() (interface{}, error) {
+		value, err := q.sets.load(fmt.Sprintf("%s/%X", chain.ChainID, header.ValidatorsHash), func() (interface{}, error) {
+			return r.fetchSet(ctx, chain, q.lowestHeight(chain, header))
+		})
+		if err != nil {
+			return nil, err
+		}
+		set := value.(*graphqlSet)
+		rounds := header.Height - set.height
+		if rounds == 0 {
+			return set.resolver(r, chain, set.height, set.tm), nil
+		}
+		if rounds > 0 && rounds <= MAX_PRIORITY_ROUNDS && len(set.validators) > 0 {
+			tm := set.tm.CopyIncrementProposerPriority(int32(rounds))
+			if bytes.Equal(tm.Proposer.Address, header.ProposerAddress) {
+				return set.resolver(r, chain, header.Height, tm), nil
+			}
+		}
+		set, err = r.fetchSet(ctx, chain, header.Height)
+		if err != nil {
+			return nil, err
+		}
+		return set.resolver(r, chain, set.height, set.tm), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*validatorSetResolver), nil
+}
+
+// operators loads the staking operators of chain by consensus address, once per query
+func (r *queryResolver) operators(ctx context.Context, chain *Chain) (map[string]*types.OperatorInfo, error) {
+	q := queryFromContext(ctx)
+	value, err := q.operators.load(chain.ChainID, func() (interface{}, error) {
+		_, staking, done, err := r.dial(chain)
+		if err != nil {
+			return nil, err
+		}
+		defer done()
+		ops, err := operators(context.WithValue(ctx, chainKey{}, chain), &chargedStaking{QueryClient: staking, query: q})
+		if err != nil {
+			return nil, graphqlError(err)
+		}
+		return ops, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(map[string]*types.OperatorInfo), nil
+}
+
+// Block resolves Query.block
+func (r *queryResolver) Block(ctx context.Context, args struct {
+	Height  *Int64
+	ChainID *string
+}) (*blockResolver, error) {
+	ctx, chain, err := graphqlChain(ctx, args.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	var height int64
+	if args.Height != nil {
+		if height = int64(*args.Height); height <= 0 {
+			return nil, fmt.Errorf("height must be positive, got %d", height)
+		}
+	}
+	return r.block(ctx, chain, height)
+}
+
+// Blocks resolves Query.blocks, loading the blocks at once
+func (r *queryResolver) Blocks(ctx context.Context, args struct {
+	Heights []Int64
+	ChainID *string
+}) ([]*blockResolver, error) {
+	ctx, chain, err := graphqlChain(ctx, args.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	if len(args.Heights) > MAX_QUERY_BLOCKS {
+		return nil, fmt.Errorf("at most %d heights can be queried, got %d", MAX_QUERY_BLOCKS, len(args.Heights))
+	}
+	for _, height := range args.Heights {
+		if height <= 0 {
+			return nil, fmt.Errorf("height must be positive, got %d", height)
+		}
+	}
+	blocks := make([]*blockResolver, len(args.Heights))
+	errs := make([]error, len(args.Heights))
+	var wg sync.WaitGroup
+	for i, height := range args.Heights {
+		wg.Add(1)
+		go func(i int, height int64) {
+			defer wg.Done()
+			blocks[i], errs[i] = r.block(ctx, chain, height)
+		}(i, int64(height))
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return blocks, nil
+}
+
+// ValidatorSet resolves Query.validatorSet
+func (r *queryResolver) ValidatorSet(ctx context.Context, args struct {
+	Height  *Int64
+	ChainID *string
+}) (*validatorSetResolver, error) {
+	ctx, chain, err := graphqlChain(ctx, args.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	var height int64
+	if args.Height != nil {
+		if height = int64(*args.Height); height <= 0 {
+			return nil, fmt.Errorf("height must be positive, got %d", height)
+		}
+	}
+	return r.validatorSet(ctx, chain, height)
+}
+
+// Tx resolves Query.tx
+func (r *queryResolver) Tx(ctx context.Context, args struct {
+	Hash    string
+	ChainID *string
+}) (*txResultResolver, error) {
+	ctx, chain, err := graphqlChain(ctx, args.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	q := queryFromContext(ctx)
+	value, err := q.txs.load(chain.ChainID+"/"+args.Hash, func() (interface{}, error) {
+		if err := q.charge(); err != nil {
+			return nil, err
+		}
+		resp, err := r.service.GetTx(ctx, &types.GetTxRequest{Hash: args.Hash, ChainId: chain.ChainID})
+		if err != nil {
+			return nil, graphqlError(err)
+		}
+		return &txResultResolver{tx: resp.Tx}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*txResultResolver), nil
+}
+
+// blockResolver resolves the Block type
+type blockResolver struct {
+	query *queryResolver
+	chain *Chain
+	id    *tmproto.BlockID
+	block *tmproto.Block
+}
+
+func (b *blockResolver) ChainID() string {
+	return b.chain.ChainID
+}
+
+func (b *blockResolver) Height() Int64 {
+	return Int64(b.block.Header.Height)
+}
+
+func (b *blockResolver) Hash() string {
+	if b.id == nil {
+		return ""
+	}
+	return fmt.Sprintf("%X", b.id.Hash)
+}
+
+func (b *blockResolver) Time() string {
+	return b.block.Header.Time.UTC().Format(time.RFC3339Nano)
+}
+
+func (b *blockResolver) TxCount() int32 {
+	return int32(len(b.block.Data.Txs))
+}
+
+func (b *blockResolver) ProposerAddress() string {
+	return b.chain.consAddress(b.block.Header.ProposerAddress)
+}
+
+// Proposer resolves the proposer from the validator-set of the block
+func (b *blockResolver) Proposer(ctx context.Context) (*validatorResolver, error) {
+	set, err := b.ValidatorSet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	address := b.ProposerAddress()
+	for i, v := range set.validators {
+		if v.ConsensusAddress == address {
+			return set.validator(i), nil
+		}
+	}
+	return nil, nil
+}
+
+// ValidatorSet resolves the validator-set signing the block, shared by its proposer and the validatorSet at its height
+func (b *blockResolver) ValidatorSet(ctx context.Context) (*validatorSetResolver, error) {
+	ctx = context.WithValue(ctx, chainKey{}, b.chain)
+	return b.query.blockValidatorSet(ctx, b.chain, b.block.Header)
+}
+
+// Txs decodes the txs of the block, without a GrpcQueryService call
+func (b *blockResolver) Txs(ctx context.Context) ([]*txResolver, error) {
+	if err := queryFromContext(ctx).charge(); err != nil {
+		return nil, err
+	}
+	decoded := b.chain.decodeTxs(b.block.Data.Txs)
+	ans := make([]*txResolver, 0, len(decoded))
+	for _, tx := range decoded {
+		ans = append(ans, &txResolver{tx: tx})
+	}
+	return ans, nil
+}
+
+// validatorSetResolver resolves the ValidatorSet type. The validators are shared by the heights of the same
+// validators hash, priorities holds their proposer priorities at height.
+type validatorSetResolver struct {
+	query      *queryResolver
+	chain      *Chain
+	height     int64
+	total      int64
+	validators []*types.Validator
+	priorities []int64
+}
+
+func (s *validatorSetResolver) Height() Int64 {
+	return Int64(s.height)
+}
+
+func (s *validatorSetResolver) TotalVotingPower() Int64 {
+	return Int64(s.total)
+}
+
+func (s *validatorSetResolver) Validators() []*validatorResolver {
+	ans := make([]*validatorResolver, 0, len(s.validators))
+	for i := range s.validators {
+		ans = append(ans, s.validator(i))
+	}
+	return ans
+}
+
+// validator returns the resolver of the validator at index i
+func (s *validatorSetResolver) validator(i int) *validatorResolver {
+	return &validatorResolver{query: s.query, chain: s.chain, v: s.validators[i], priority: s.priorities[i]}
+}
+
+// validatorResolver resolves the Validator type
+type validatorResolver struct {
+	query    *queryResolver
+	chain    *Chain
+	v        *types.Validator
+	priority int64
+}
+
+func (v *validatorResolver) Address() string {
+	return v.v.ConsensusAddress
+}
+
+func (v *validatorResolver) PubKeyBase64() string {
+	return v.v.PubKeyBase64
+}
+
+func (v *validatorResolver) VotingPower() Int64 {
+	return Int64(v.v.VotingPower)
+}
+
+func (v *validatorResolver) ProposerPriority() Int64 {
+	return Int64(v.priority)
+}
+
+// operator returns the staking operator of the validator, nil when it has none
+func (v *validatorResolver) operator(ctx context.Context) (*types.OperatorInfo, error) {
+	ops, err := v.query.operators(ctx, v.chain)
+	if err != nil {
+		return nil, err
+	}
+	return ops[v.v.ConsensusAddress], nil
+}
+
+func (v *validatorResolver) OperatorAddress(ctx context.Context) (*string, error) {
+	op, err := v.operator(ctx)
+	if op == nil {
+		return nil, err
+	}
+	return &op.OperatorAddress, nil
+}
+
+func (v *validatorResolver) Moniker(ctx context.Context) (*string, error) {
+	op, err := v.operator(ctx)
+	if op == nil {
+		return nil, err
+	}
+	return &op.Moniker, nil
+}
+
+func (v *validatorResolver) Jailed(ctx context.Context) (*bool, error) {
+	op, err := v.operator(ctx)
+	if op == nil {
+		return nil, err
+	}
+	return &op.Jailed, nil
+}
+
+func (v *validatorResolver) Status(ctx context.Context) (*string, error) {
+	op, err := v.operator(ctx)
+	if op == nil {
+		return nil, err
+	}
+	return &op.Status, nil
+}
+
+// txResolver resolves the Tx type
+type txResolver struct {
+	tx *types.DecodedTx
+}
+
+func (t *txResolver) Hash() string {
+	return t.tx.Hash
+}
+
+func (t *txResolver) MessageTypes() []string {
+	ans := make([]string, 0, len(t.tx.Messages))
+	for _, msg := range t.tx.Messages {
+		ans = append(ans, msg.TypeUrl)
+	}
+	return ans
+}
+
+func (t *txResolver) Memo() string {
+	return t.tx.Memo
+}
+
+func (t *txResolver) Signers() []string {
+	return t.tx.Signers
+}
+
+func (t *txResolver) JSON() string {
+	return t.tx.Json
+}
+
+func (t *txResolver) DecodeError() *string {
+	if t.tx.DecodeError == "" {
+		return nil
+	}
+	return &t.tx.DecodeError
+}
+
+// txResultResolver resolves the TxResult type
+type txResultResolver struct {
+	tx *types.TxResult
+}
+
+func (t *txResultResolver) Hash() string {
+	return t.tx.Hash
+}
+
+func (t *txResultResolver) Height() Int64 {
+	return Int64(t.tx.Height)
+}
+
+func (t *txResultResolver) Code() int32 {
+	return int32(t.tx.Code)
+}
+
+func (t *txResultResolver) Codespace() string {
+	return t.tx.Codespace
+}
+
+func (t *txResultResolver) GasWanted() Int64 {
+	return Int64(t.tx.GasWanted)
+}
+
+func (t *txResultResolver) GasUsed() Int64 {
+	return Int64(t.tx.GasUsed)
+}
+
+func (t *txResultResolver) RawLog() string {
+	return t.tx.RawLog
+}
+
+func (t *txResultResolver) Timestamp() string {
+	return t.tx.Timestamp
+}
+
+func (t *txResultResolver) Tx() *txResolver {
+	if t.tx.Tx == nil {
+		return nil
+	}
+	return &txResolver{tx: t.tx.Tx}
+}
@@ -0,0 +1,282 @@
+// This file contains tests for the GraphQL API.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	types "grpc_server4/proto/generated"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"google.golang.org/grpc"
+)
+
+// graphqlService serves the blocks of a test chain to the GraphQL resolvers, and its validator-sets and staking
+// validators as their upstream, counting the calls. The proposer priorities of the validator-sets advance every
+// block while they do not change, and the blocks are proposed accordingly.
+type graphqlService struct {
+	types.UnimplementedGrpcQueryServiceServer
+	chain   *testChain
+	blocks  map[int64]*tmproto.Block
+	sets    map[int64][]*tmservice.Validator
+	staking *fakeStakingService
+
+	mtx   sync.Mutex
+	calls map[string]int
+}
+
+func newGraphQLService(t *testing.T, chain *testChain) *graphqlService {
+	s := &graphqlService{
+		chain:   chain,
+		blocks:  make(map[int64]*tmproto.Block),
+		sets:    make(map[int64][]*tmservice.Validator),
+		staking: testStakingService(t, 4),
+		calls:   map[string]int{},
+	}
+	var set *tmtypes.ValidatorSet
+	for h := int64(1); h <= int64(len(chain.blocks)); h++ {
+		if h > 1 && chain.sets[h] == chain.sets[h-1] {
+			set = set.CopyIncrementProposerPriority(1)
+		} else {
+			set = chain.sets[h]
+		}
+		s.sets[h] = tmserviceValidators(set)
+		block := *chain.blocks[h]
+		block.Header.ProposerAddress = set.GetProposer().Address
+		s.blocks[h] = &block
+	}
+	return s
+}
+
+func (s *graphqlService) count(method string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.calls[method]++
+}
+
+func (s *graphqlService) GetBlockByHeight(ctx context.Context, req *types.GetBlockByHeightRequest) (*types.GetBlockByHeightResponse, error) {
+	s.count("GetBlockByHeight")
+	block, ok := s.blocks[req.Height]
+	if !ok {
+		return nil, fmt.Errorf("no block at height %d", req.Height)
+	}
+	return &types.GetBlockByHeightResponse{BlockId: s.chain.ids[req.Height], Block: block}, nil
+}
+
+// dial connects the resolvers to the validator-sets and the staking validators of the service
+func (s *graphqlService) dial(chain *Chain) (tmservice.ServiceClient, stakingtypes.QueryClient, func(), error) {
+	return &graphqlUpstream{service: s}, &graphqlStaking{QueryClient: s.staking, service: s}, func() {}, nil
+}
+
+// graphqlUpstream serves the validator-sets of a graphqlService in one page
+type graphqlUpstream struct {
+	tmservice.ServiceClient
+	service *graphqlService
+}
+
+func (u *graphqlUpstream) GetValidatorSetByHeight(ctx context.Context, in *tmservice.GetValidatorSetByHeightRequest, opts ...grpc.CallOption) (*tmservice.GetValidatorSetByHeightResponse, error) {
+	u.service.count("GetValidatorSetByHeight")
+	set, ok := u.service.sets[in.Height]
+	if !ok {
+		return nil, fmt.Errorf("no validator set at height %d", in.Height)
+	}
+	return &tmservice.GetValidatorSetByHeightResponse{BlockHeight: in.Height, Validators: set, Pagination: &query.PageResponse{Total: uint64(len(set))}}, nil
+}
+
+// graphqlStaking counts the staking validators calls of a graphqlService
+type graphqlStaking struct {
+	stakingtypes.QueryClient
+	service *graphqlService
+}
+
+func (s *graphqlStaking) Validators(ctx context.Context, in *stakingtypes.QueryValidatorsRequest, opts ...grpc.CallOption) (*stakingtypes.QueryValidatorsResponse, error) {
+	s.service.count("Validators")
+	return s.QueryClient.Validators(ctx, in, opts...)
+}
+
+// graphqlExec runs query with variables on a GraphQL handler served by service
+func graphqlExec(t *testing.T, service *graphqlService, query string, variables map[string]interface{}) (map[string]interface{}, []string) {
+	server := httptest.NewServer(newGraphQLHandler(service, service.dial))
+	defer server.Close()
+	body, err := json.Marshal(&graphqlRequest{Query: query, Variables: variables})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post(server.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var ans struct {
+		Data   map[string]interface{}
+		Errors []struct{ Message string }
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&ans); err != nil {
+		t.Fatal(err)
+	}
+	var errs []string
+	for _, e := range ans.Errors {
+		errs = append(errs, e.Message)
+	}
+	return ans.Data, errs
+}
+
+// TestGraphQLBlock tests a block with its proposer moniker and tx count in one query
+func TestGraphQLBlock(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	chain := newTestChain(t, 4, 3)
+	service := newGraphQLService(t, chain)
+	data, errs := graphqlExec(t, service, `{
+		block(height: 2) {
+			height hash txCount proposerAddress
+			proposer { moniker votingPower }
+			validatorSet { totalVotingPower }
+			txs { decodeError }
+		}
+	}`, nil)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	block := data["block"].(map[string]interface{})
+	if block["height"] != "2" || block["hash"] != fmt.Sprintf("%X", chain.ids[2].Hash) || block["txCount"] != float64(1) {
+		t.Errorf("expected block 2 with 1 tx, got %v", block)
+	}
+	proposer, ok := block["proposer"].(map[string]interface{})
+	if !ok || proposer["moniker"] == nil || !strings.HasPrefix(proposer["moniker"].(string), "validator-") {
+		t.Errorf("expected the proposer with its moniker, got %v", block["proposer"])
+	}
+	if block["validatorSet"].(map[string]interface{})["totalVotingPower"] != "10" {
+		t.Errorf("expected a total voting power of 10, got %v", block["validatorSet"])
+	}
+	if txs := block["txs"].([]interface{}); len(txs) != 1 || txs[0].(map[string]interface{})["decodeError"] == nil {
+		t.Errorf("expected the undecodable test tx, got %v", txs)
+	}
+	if service.calls["GetBlockByHeight"] != 1 || service.calls["GetValidatorSetByHeight"] != 1 {
+		t.Errorf("expected the proposer and the validator-set to share one call, got %v", service.calls)
+	}
+
+	_, errs = graphqlExec(t, service, `{ block(height: 9) { height } }`, nil)
+	if len(errs) != 1 || !strings.Contains(errs[0], "no block at height 9") {
+		t.Errorf("expected the service error, got %v", errs)
+	}
+	_, errs = graphqlExec(t, service, `{ block(height: 1, chainId: "cosmoshub-4") { height } }`, nil)
+	if len(errs) != 1 || !strings.Contains(errs[0], "unknown chain") {
+		t.Errorf("expected the unknown chain rejected, got %v", errs)
+	}
+}
+
+// graphqlBlocks runs a blocks query at heights for the proposer moniker and the validator-set of every block, and
+// checks they are the ones of the block height
+func graphqlBlocks(t *testing.T, service *graphqlService, heights ...interface{}) {
+	query := `query($heights: [Int64!]!) {
+		blocks(heights: $heights) {
+			height proposer { address moniker } validatorSet { height validators { address proposerPriority } }
+		}
+	}`
+	data, errs := graphqlExec(t, service, query, map[string]interface{}{"heights": heights})
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	blocks := data["blocks"].([]interface{})
+	if len(blocks) != len(heights) {
+		t.Fatalf("expected %d blocks, got %v", len(heights), blocks)
+	}
+	for _, b := range blocks {
+		block := b.(map[string]interface{})
+		height, _ := strconv.ParseInt(block["height"].(string), 10, 64)
+		proposer, _ := block["proposer"].(map[string]interface{})
+		if proposer == nil || proposer["address"] != DefaultChain.consAddress(service.blocks[height].Header.ProposerAddress) {
+			t.Errorf("expected the proposer of block %d, got %v", height, block["proposer"])
+		}
+		set := block["validatorSet"].(map[string]interface{})
+		if set["height"] != block["height"] {
+			t.Errorf("expected the validator-set at height %d, got %v", height, set["height"])
+		}
+		for i, v := range set["validators"].([]interface{}) {
+			if priority := v.(map[string]interface{})["proposerPriority"]; priority != fmt.Sprint(service.sets[height][i].ProposerPriority) {
+				t.Errorf("expected the priority %d of validator %d at height %d, got %v", service.sets[height][i].ProposerPriority, i, height, priority)
+			}
+		}
+	}
+}
+
+// TestGraphQLBatching tests that the blocks signed by the same validator-set share one validator-set call, with the
+// proposer priorities of their height, and that the staking operators are read once per query
+func TestGraphQLBatching(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	for _, c := range []struct {
+		rotate int
+		sets   int
+	}{{0, 1}, {2, 2}} {
+		service := newGraphQLService(t, newRotatingTestChain(t, 4, 4, c.rotate))
+		graphqlBlocks(t, service, 1, "2", 3, 4, 4)
+		if service.calls["GetBlockByHeight"] != 4 || service.calls["GetValidatorSetByHeight"] != c.sets {
+			t.Errorf("rotate %d: expected 4 block calls and %d validator-set calls, got %v", c.rotate, c.sets, service.calls)
+		}
+		// the test staking service serves a page per validator
+		if service.calls["Validators"] != 4 {
+			t.Errorf("rotate %d: expected the staking validators read once, got %v", c.rotate, service.calls)
+		}
+	}
+}
+
+// TestGraphQLPriorities tests that the validator-set of a block is read at its height when the priorities advanced to
+// it do not elect its proposer, or are read above it
+func TestGraphQLPriorities(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	service := newGraphQLService(t, newTestChain(t, 4, 4))
+	// the set of 3 was updated and changed back since 1, so its priorities are not the ones of 1 advanced
+	block := *service.blocks[3]
+	block.Header.ProposerAddress = service.chain.set.GetProposer().Address
+	service.blocks[3], service.sets[3] = &block, tmserviceValidators(service.chain.set)
+	if advanced := service.chain.set.CopyIncrementProposerPriority(2); bytes.Equal(advanced.Proposer.Address, block.Header.ProposerAddress) {
+		t.Fatal("expected the advanced priorities to elect another proposer")
+	}
+	graphqlBlocks(t, service, 1, 3)
+	if service.calls["GetValidatorSetByHeight"] != 2 {
+		t.Errorf("expected the validator-set of 3 read at its height, got %v", service.calls)
+	}
+}
+
+// TestGraphQLCost tests that a query is cut once its cost exceeds MAX_QUERY_COST, and the number of heights bounded
+func TestGraphQLCost(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	chain := newTestChain(t, 1, 2*MAX_QUERY_BLOCKS)
+	service := newGraphQLService(t, chain)
+	var a, b []interface{}
+	for h := 1; h <= MAX_QUERY_BLOCKS; h++ {
+		a, b = append(a, h), append(b, MAX_QUERY_BLOCKS+h)
+	}
+	query := `query($a: [Int64!]!, $b: [Int64!]!) {
+		a: blocks(heights: $a) { height }
+		b: blocks(heights: $b) { height validatorSet { height } }
+	}`
+	_, errs := graphqlExec(t, service, query, map[string]interface{}{"a": a, "b": b})
+	if len(errs) == 0 || !strings.Contains(errs[0], fmt.Sprintf("query cost exceeds %d", MAX_QUERY_COST)) {
+		t.Errorf("expected the query cost exceeded, got %v", errs)
+	}
+
+	_, errs = graphqlExec(t, service, `query($a: [Int64!]!) { blocks(heights: $a) { height } }`, map[string]interface{}{"a": append(a, 1)})
+	if len(errs) != 1 || !strings.Contains(errs[0], "at most 50 heights") {
+		t.Errorf("expected too many heights rejected, got %v", errs)
+	}
+}
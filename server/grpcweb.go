@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"google.golang.org/grpc"
+)
+
+// GRPC_WEB_HEADERS are the request headers of the gRPC-Web clients and of the server allowed in cross-origin calls
+var GRPC_WEB_HEADERS = []string{"Content-Type", "X-Grpc-Web", "X-User-Agent", "Grpc-Timeout", "Grpc-Accept-Encoding", CHAIN_ID_HEADER, HEIGHT_HEADER}
+
+// GRAPHQL_CORS_HEADERS are the request headers allowed in cross-origin GraphQL queries
+var GRAPHQL_CORS_HEADERS = []string{"Content-Type", CHAIN_ID_HEADER}
+
+// GRPCWebConfig serves GrpcQueryService to browsers with gRPC-Web on the HTTP of every listener
+type GRPCWebConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// AllowedOrigins are the origins of the pages allowed to make cross-origin calls, "*" allows any and none
+	// are allowed when empty
+	AllowedOrigins []string `yaml:"allowed_origins"`
+}
+
+// allowedOrigin reports whether the running config lets the pages of origin make gRPC-Web calls
+func allowedOrigin(origin string) bool {
+	for _, allowed := range runningConfig().GRPCWeb.AllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// grpcWebHandler serves the gRPC-Web calls and their CORS preflights of the methods of grpcServer while the
+// running config enables gRPC-Web, and passes the other requests to next
+func grpcWebHandler(grpcServer *grpc.Server, next http.Handler) http.Handler {
+	web := grpcweb.WrapServer(grpcServer,
+		grpcweb.WithOriginFunc(allowedOrigin),
+		grpcweb.WithAllowedRequestHeaders(GRPC_WEB_HEADERS),
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if runningConfig().GRPCWeb.Enabled && (web.IsGrpcWebRequest(r) || web.IsAcceptableGrpcCorsRequest(r)) {
+			web.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// corsHandler lets the pages of the origins allowed for gRPC-Web query next, a GET and POST handler, answering
+// their CORS preflights
+func corsHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !allowedOrigin(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(GRAPHQL_CORS_HEADERS, ", "))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,177 @@
+// This file contains tests for the gRPC-Web calls and their CORS preflights.
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	types "grpc_server4/proto/generated"
+
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// TEST_ORIGIN is the origin of the page of the tests
+const TEST_ORIGIN = "https://explorer.example"
+
+// grpcWebCall posts req to method of the server at url like a gRPC-Web browser client, and returns the status
+// and the data and trailer frames of the response
+func grpcWebCall(t *testing.T, url, method string, req proto.Message) (int, []byte, string) {
+	bz, err := proto.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := make([]byte, 5, 5+len(bz))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(bz)))
+	httpReq, err := http.NewRequest(http.MethodPost, url+method, bytes.NewReader(append(frame, bz...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpReq.Header.Set("Content-Type", "application/grpc-web+proto")
+	httpReq.Header.Set("Origin", TEST_ORIGIN)
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data []byte
+	var trailer string
+	for resp.StatusCode == http.StatusOK && len(body) >= 5 {
+		n := binary.BigEndian.Uint32(body[1:5])
+		if int(n) > len(body)-5 {
+			t.Fatalf("truncated gRPC-Web frame of %d bytes", n)
+		}
+		if body[0]&0x80 != 0 {
+			trailer = string(body[5 : 5+n])
+		} else {
+			data = body[5 : 5+n]
+		}
+		body = body[5+n:]
+	}
+	return resp.StatusCode, data, trailer
+}
+
+// TestGRPCWeb tests that gRPC-Web calls are served while enabled by the running config
+func TestGRPCWeb(t *testing.T) {
+	defer func() { LoadedConfig = &Config{} }()
+
+	grpcServer := grpc.NewServer()
+	block := &types.GetBlockByHeightResponse{Block: &tmproto.Block{Header: tmproto.Header{Height: 8700000}}}
+	types.RegisterGrpcQueryServiceServer(grpcServer, &bigBlockService{resp: block})
+	node := httptest.NewServer(httpHandler(grpcServer))
+	defer node.Close()
+	method := "/proto.GrpcQueryService/GetBlockByHeight"
+
+	if code, _, _ := grpcWebCall(t, node.URL, method, &types.GetBlockByHeightRequest{Height: 8700000}); code != http.StatusNotFound {
+		t.Errorf("expected gRPC-Web disabled by default, got status %d", code)
+	}
+
+	LoadedConfig = &Config{GRPCWeb: GRPCWebConfig{Enabled: true, AllowedOrigins: []string{TEST_ORIGIN}}}
+	code, data, trailer := grpcWebCall(t, node.URL, method, &types.GetBlockByHeightRequest{Height: 8700000})
+	if code != http.StatusOK || !strings.Contains(trailer, "grpc-status: 0") {
+		t.Fatalf("expected the call to succeed, got status %d and trailer %q", code, trailer)
+	}
+	var resp types.GetBlockByHeightResponse
+	if err := proto.Unmarshal(data, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Block.Header.Height != 8700000 {
+		t.Errorf("expected the block at 8700000, got %d", resp.Block.Header.Height)
+	}
+
+	// the health check is still served
+	health, err := http.Get(node.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	health.Body.Close()
+	if health.StatusCode != http.StatusOK {
+		t.Errorf("expected the health check ok, got %d", health.StatusCode)
+	}
+}
+
+// TestGRPCWebCORS tests that the preflights are answered for the allowed origins and the x-chain-id header only
+func TestGRPCWebCORS(t *testing.T) {
+	defer func() { LoadedConfig = &Config{} }()
+	LoadedConfig = &Config{GRPCWeb: GRPCWebConfig{Enabled: true, AllowedOrigins: []string{TEST_ORIGIN}}}
+
+	grpcServer := grpc.NewServer()
+	types.RegisterGrpcQueryServiceServer(grpcServer, &bigBlockService{})
+	node := httptest.NewServer(httpHandler(grpcServer))
+	defer node.Close()
+
+	preflight := func(origin string) http.Header {
+		req, err := http.NewRequest(http.MethodOptions, node.URL+"/proto.GrpcQueryService/GetBlockByHeight", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "content-type,x-grpc-web,"+CHAIN_ID_HEADER)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.Header
+	}
+	if got := preflight(TEST_ORIGIN).Get("Access-Control-Allow-Origin"); got != TEST_ORIGIN {
+		t.Errorf("expected %s allowed, got %q", TEST_ORIGIN, got)
+	}
+	if got := preflight("https://other.example").Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("expected another origin denied, got %q", got)
+	}
+
+	LoadedConfig = &Config{GRPCWeb: GRPCWebConfig{Enabled: true, AllowedOrigins: []string{"*"}}}
+	if got := preflight("https://other.example").Get("Access-Control-Allow-Origin"); got != "https://other.example" {
+		t.Errorf("expected any origin allowed, got %q", got)
+	}
+}
+
+// TestGraphQLCORS tests that the pages of the allowed origins can query /graphql
+func TestGraphQLCORS(t *testing.T) {
+	defer func() { LoadedConfig = &Config{} }()
+	LoadedConfig = &Config{GRPCWeb: GRPCWebConfig{Enabled: true, AllowedOrigins: []string{TEST_ORIGIN}}}
+
+	node := httptest.NewServer(httpHandler(grpc.NewServer()))
+	defer node.Close()
+
+	request := func(method, origin string) *http.Response {
+		req, err := http.NewRequest(method, node.URL+"/graphql", strings.NewReader(`{"query":"{ __typename }"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Content-Type", "application/json")
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			req.Header.Set("Access-Control-Request-Headers", "content-type,"+CHAIN_ID_HEADER)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	resp := request(http.MethodOptions, TEST_ORIGIN)
+	if resp.StatusCode != http.StatusNoContent || resp.Header.Get("Access-Control-Allow-Origin") != TEST_ORIGIN || !strings.Contains(resp.Header.Get("Access-Control-Allow-Headers"), CHAIN_ID_HEADER) {
+		t.Errorf("expected the preflight of %s answered, got %d %v", TEST_ORIGIN, resp.StatusCode, resp.Header)
+	}
+	if resp = request(http.MethodPost, TEST_ORIGIN); resp.StatusCode != http.StatusOK || resp.Header.Get("Access-Control-Allow-Origin") != TEST_ORIGIN {
+		t.Errorf("expected the query of %s allowed, got %d %v", TEST_ORIGIN, resp.StatusCode, resp.Header)
+	}
+	if got := request(http.MethodOptions, "https://other.example").Header.Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("expected another origin denied, got %q", got)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// HEIGHT_HEADER is the cosmos metadata header pinning the state height of a query, also set in the response
+// trailers to the height the upstream served
+const HEIGHT_HEADER = grpctypes.GRPCBlockHeightHeader
+
+// heightKey is the context key of the state height of a request
+type heightKey struct{}
+
+// queryHeight is the state height of a request: the height it pinned, zero for the latest one, and the height
+// the upstream served its first state query at. A queryHeight reading the state at another height of the request
+// also records the height served in the one of the request, its parent.
+type queryHeight struct {
+	mtx    sync.Mutex
+	pinned int64
+	served int64
+	parent *queryHeight
+}
+
+// height returns the height state queries are pinned to: the one requested, else the one of the first state
+// query served, so every page and query of a request reads the same state
+func (h *queryHeight) height() int64 {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if h.pinned > 0 {
+		return h.pinned
+	}
+	return h.served
+}
+
+// serve records the height the upstream served a state query at
+func (h *queryHeight) serve(height int64) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if h.served == 0 {
+		h.served = height
+	}
+	if h.parent != nil {
+		h.parent.serve(height)
+	}
+}
+
+// servedHeight returns the height the upstream served the state queries at, zero when none was made
+func (h *queryHeight) servedHeight() int64 {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.served
+}
+
+// heightInterceptor pins the state queries of a request to its state_height field, or else to the height of the
+// x-cosmos-block-height header, and sets the height the upstream served in the response trailers
+func heightInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	h := &queryHeight{}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(HEIGHT_HEADER); len(values) > 0 {
+			height, err := strconv.ParseInt(values[0], 10, 64)
+			if err != nil || height < 0 {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid %s header %q", HEIGHT_HEADER, values[0])
+			}
+			h.pinned = height
+		}
+	}
+	if r, ok := req.(interface{ GetStateHeight() int64 }); ok && r.GetStateHeight() != 0 {
+		if r.GetStateHeight() < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid state height %d", r.GetStateHeight())
+		}
+		h.pinned = r.GetStateHeight()
+	}
+	resp, err := handler(context.WithValue(ctx, heightKey{}, h), req)
+	if served := h.servedHeight(); served > 0 {
+		// fails only outside of a gRPC call, as in tests
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(HEIGHT_HEADER, strconv.FormatInt(served, 10)))
+	}
+	return resp, err
+}
+
+// atStateHeight returns the context of state queries reading the state at height unless the request pins another
+// one. Each height gets its own queryHeight, so a request reading the state at several heights reads each at its own.
+func atStateHeight(ctx context.Context, height int64) context.Context {
+	h, ok := ctx.Value(heightKey{}).(*queryHeight)
+	if ok && h.pinned > 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, heightKey{}, &queryHeight{pinned: height, parent: h})
+}
+
+// stateQuery makes a state query of the upstream with call, pinned to the state height of the request and
+// recording the height it was served at
+func stateQuery(ctx context.Context, call func(ctx context.Context, opts ...grpc.CallOption) error) error {
+	h, ok := ctx.Value(heightKey{}).(*queryHeight)
+	if !ok {
+		return call(ctx)
+	}
+	if height := h.height(); height > 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, HEIGHT_HEADER, strconv.FormatInt(height, 10))
+	}
+	var header metadata.MD
+	if err := call(ctx, grpc.Header(&header)); err != nil {
+		return err
+	}
+	if values := header.Get(HEIGHT_HEADER); len(values) > 0 {
+		if height, err := strconv.ParseInt(values[0], 10, 64); err == nil {
+			h.serve(height)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// This file contains tests for the height-pinned state queries.
+package main
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	types "grpc_server4/proto/generated"
+
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// heightStakingService is a staking service recording the height every query pinned, and serving the pinned
+// height or else latest in the x-cosmos-block-height header like the cosmos gRPC server
+type heightStakingService struct {
+	*fakeStakingService
+	latest int64
+	pinned []string
+}
+
+func (f *heightStakingService) Validators(ctx context.Context, in *stakingtypes.QueryValidatorsRequest, opts ...grpc.CallOption) (*stakingtypes.QueryValidatorsResponse, error) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	served := strconv.FormatInt(f.latest, 10)
+	if values := md.Get(HEIGHT_HEADER); len(values) > 0 {
+		served = values[0]
+	}
+	f.pinned = append(f.pinned, served)
+	for _, opt := range opts {
+		if header, ok := opt.(grpc.HeaderCallOption); ok {
+			*header.HeaderAddr = metadata.Pairs(HEIGHT_HEADER, served)
+		}
+	}
+	return f.fakeStakingService.Validators(ctx, in, opts...)
+}
+
+// TestHeightInterceptor tests that the state height comes from the state_height field, else from the header
+func TestHeightInterceptor(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	var pinned int64
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		pinned = ctx.Value(heightKey{}).(*queryHeight).height()
+		return nil, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/GrpcQueryService/GetProposers"}
+	header := metadata.NewIncomingContext(context.Background(), metadata.Pairs(HEIGHT_HEADER, "100"))
+
+	cases := []struct {
+		name   string
+		ctx    context.Context
+		req    *types.GetProposersRequest
+		pinned int64
+	}{
+		{"latest", context.Background(), &types.GetProposersRequest{}, 0},
+		{"header", header, &types.GetProposersRequest{}, 100},
+		{"field", context.Background(), &types.GetProposersRequest{StateHeight: 200}, 200},
+		{"field over header", header, &types.GetProposersRequest{StateHeight: 200}, 200},
+	}
+	for _, c := range cases {
+		if _, err := heightInterceptor(c.ctx, c.req, info, handler); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if pinned != c.pinned {
+			t.Errorf("%s: expected height %d, got %d", c.name, c.pinned, pinned)
+		}
+	}
+
+	invalid := metadata.NewIncomingContext(context.Background(), metadata.Pairs(HEIGHT_HEADER, "latest"))
+	if _, err := heightInterceptor(invalid, &types.GetProposersRequest{}, info, handler); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an invalid header, got %v", err)
+	}
+	if _, err := heightInterceptor(context.Background(), &types.GetProposersRequest{StateHeight: -1}, info, handler); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for a negative state height, got %v", err)
+	}
+}
+
+// TestStateQueryHeight tests that the state queries of a request are forwarded pinned and record the served height
+func TestStateQueryHeight(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	// pinned by the request
+	staking := &heightStakingService{fakeStakingService: testStakingService(t, 2), latest: 500}
+	h := &queryHeight{pinned: 100}
+	if _, err := operators(context.WithValue(context.Background(), heightKey{}, h), staking); err != nil {
+		t.Fatal(err)
+	}
+	if len(staking.pinned) != 2 || staking.pinned[0] != "100" || staking.pinned[1] != "100" || h.servedHeight() != 100 {
+		t.Errorf("expected every page pinned to 100, got %v served at %d", staking.pinned, h.servedHeight())
+	}
+
+	// the later pages are pinned to the height the first one was served at
+	staking = &heightStakingService{fakeStakingService: testStakingService(t, 2), latest: 500}
+	h = &queryHeight{}
+	if _, err := operators(context.WithValue(context.Background(), heightKey{}, h), staking); err != nil {
+		t.Fatal(err)
+	}
+	if staking.pinned[1] != "500" || h.servedHeight() != 500 {
+		t.Errorf("expected the pages pinned to the latest height 500, got %v served at %d", staking.pinned, h.servedHeight())
+	}
+}
+
+// failingStakingService is a staking service whose queries fail
+type failingStakingService struct {
+	stakingtypes.QueryClient
+}
+
+func (f *failingStakingService) Validators(ctx context.Context, in *stakingtypes.QueryValidatorsRequest, opts ...grpc.CallOption) (*stakingtypes.QueryValidatorsResponse, error) {
+	return nil, status.Error(codes.Unavailable, "staking is unavailable")
+}
+
+// TestJoinOperatorsAt tests that the operators are joined at the height of the validator-set unless the request pins
+// another one, and left unset when the staking query fails
+func TestJoinOperatorsAt(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	staking := &heightStakingService{fakeStakingService: testStakingService(t, 2), latest: 500}
+	vs := DefaultChain.validators(testValidatorSet(3))
+	joinOperatorsAt(context.WithValue(context.Background(), heightKey{}, &queryHeight{}), staking, vs, 100)
+	if staking.pinned[0] != "100" || vs[0].Operator == nil || vs[1].Operator.Moniker != "validator-2" {
+		t.Errorf("expected the operators joined at 100, got %v pinned to %v", vs[0].Operator, staking.pinned)
+	}
+
+	staking = &heightStakingService{fakeStakingService: testStakingService(t, 2), latest: 500}
+	joinOperatorsAt(context.WithValue(context.Background(), heightKey{}, &queryHeight{pinned: 90}), staking, vs, 100)
+	if staking.pinned[0] != "90" {
+		t.Errorf("expected the state height of the request, got %v", staking.pinned)
+	}
+
+	vs = DefaultChain.validators(testValidatorSet(3))
+	joinOperatorsAt(context.Background(), &failingStakingService{}, vs, 100)
+	for _, v := range vs {
+		if v.Operator != nil {
+			t.Errorf("expected no operator when staking fails, got %v", v.Operator)
+		}
+	}
+}
+
+// TestJoinOperatorsAtHeights tests that a request joining the operators of sets at two heights reads each at its own
+func TestJoinOperatorsAtHeights(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	staking := &heightStakingService{fakeStakingService: testStakingService(t, 1), latest: 500}
+	h := &queryHeight{}
+	ctx := context.WithValue(context.Background(), heightKey{}, h)
+	joinOperatorsAt(ctx, staking, DefaultChain.validators(testValidatorSet(1)), 100)
+	joinOperatorsAt(ctx, staking, DefaultChain.validators(testValidatorSet(1)), 200)
+	if len(staking.pinned) != 2 || staking.pinned[0] != "100" || staking.pinned[1] != "200" {
+		t.Errorf("expected the sets joined at 100 and 200, got %v", staking.pinned)
+	}
+	if h.servedHeight() != 100 {
+		t.Errorf("expected the request served at the first height 100, got %d", h.servedHeight())
+	}
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"grpc_server4/tmrpc"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The JSON-RPC 2.0 error codes, as the tendermint RPC uses them
+const (
+	RPC_PARSE_ERROR      = -32700
+	RPC_INVALID_REQUEST  = -32600
+	RPC_METHOD_NOT_FOUND = -32601
+	RPC_INVALID_PARAMS   = -32602
+	RPC_INTERNAL_ERROR   = -32603
+)
+
+// RPC_URI_ID is the id of the response to a GET request, which has none, as tendermint answers it
+const RPC_URI_ID = "-1"
+
+// MAX_RPC_BODY bounds the body of a POSTed JSON-RPC request
+const MAX_RPC_BODY = 1 << 20
+
+// DEFAULT_PER_PAGE and MAX_PER_PAGE are the page sizes of the validators method, as tendermint pages them
+const (
+	DEFAULT_PER_PAGE = 30
+	MAX_PER_PAGE     = 100
+)
+
+// RPC_PARAMS are the names of the params of the tendermint methods served, in the order of their positional params
+var RPC_PARAMS = map[string][]string{
+	"abci_info":  {},
+	"status":     {},
+	"block":      {"height"},
+	"validators": {"height", "page", "per_page"},
+}
+
+// rpcRequest is a JSON-RPC 2.0 request whose id is kept as sent, tendermint accepting string and number ids
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response echoing the id of its request
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *tmrpc.RPCError `json:"error,omitempty"`
+}
+
+// jsonRPC serves the tendermint JSON-RPC methods abci_info, status, block and validators of the chain selected by
+// the x-chain-id header, so tendermint tools can point at the server. The blocks and validator-sets are read from
+// the gRPC upstreams of the chain, failing over to its quorum upstreams, and the latest block from the event source.
+type jsonRPC struct {
+	// dial connects to an upstream, returning the function closing the connection
+	dial func(address string) (tmservice.ServiceClient, func(), error)
+}
+
+// newJSONRPC returns the JSON-RPC front end reading from the upstreams of the chains
+func newJSONRPC() *jsonRPC {
+	return &jsonRPC{dial: dialUpstream}
+}
+
+// ServeHTTP answers the JSON-RPC requests POSTed to / and the GET requests of a method with its params in the URI
+func (j *jsonRPC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method := strings.TrimPrefix(r.URL.Path, "/")
+	_, known := RPC_PARAMS[method]
+	switch {
+	case method == "" && r.Method == http.MethodPost:
+		j.servePOST(w, r)
+	case known && r.Method == http.MethodGet:
+		j.serveURI(w, r, method)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// servePOST answers a JSON-RPC request or a batch of them. Requests without an id are notifications, left unanswered.
+func (j *jsonRPC) servePOST(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, MAX_RPC_BODY))
+	if err != nil {
+		writeRPC(w, http.StatusInternalServerError, rpcError(nil, RPC_PARSE_ERROR, "Parse error", err.Error()))
+		return
+	}
+	var requests []rpcRequest
+	batch := strings.HasPrefix(strings.TrimSpace(string(body)), "[")
+	if batch {
+		err = json.Unmarshal(body, &requests)
+	} else {
+		var request rpcRequest
+		err = json.Unmarshal(body, &request)
+		requests = []rpcRequest{request}
+	}
+	if err != nil {
+		writeRPC(w, http.StatusInternalServerError, rpcError(nil, RPC_PARSE_ERROR, "Parse error", err.Error()))
+		return
+	}
+	chain, rpcErr := rpcChain(r)
+	responses := make([]*rpcResponse, 0, len(requests))
+	for _, request := range requests {
+		if len(request.ID) == 0 {
+			continue
+		}
+		if rpcErr != nil {
+			responses = append(responses, &rpcResponse{JSONRPC: "2.0", ID: request.ID, Error: rpcErr})
+			continue
+		}
+		responses = append(responses, j.call(r.Context(), chain, &request))
+	}
+	switch {
+	case len(responses) == 0:
+		w.WriteHeader(http.StatusOK)
+	case batch:
+		writeRPC(w, http.StatusOK, responses)
+	default:
+		writeRPC(w, http.StatusOK, responses[0])
+	}
+}
+
+// serveURI answers the GET request of method, whose params are in the query string, quoted or not
+func (j *jsonRPC) serveURI(w http.ResponseWriter, r *http.Request, method string) {
+	params := make(map[string]json.RawMessage)
+	for _, name := range RPC_PARAMS[method] {
+		if value := r.URL.Query().Get(name); value != "" {
+			params[name] = json.RawMessage(strconv.Quote(strings.Trim(value, `"`)))
+		}
+	}
+	encoded, _ := json.Marshal(params)
+	request := &rpcRequest{JSONRPC: "2.0", ID: json.RawMessage(RPC_URI_ID), Method: method, Params: encoded}
+	chain, rpcErr := rpcChain(r)
+	response := &rpcResponse{JSONRPC: "2.0", ID: request.ID, Error: rpcErr}
+	if rpcErr == nil {
+		response = j.call(r.Context(), chain, request)
+	}
+	code := http.StatusOK
+	if response.Error != nil {
+		code = http.StatusInternalServerError
+	}
+	writeRPC(w, code, response)
+}
+
+// rpcChain returns the chain selected by the x-chain-id header of r, the default chain when it has none
+func rpcChain(r *http.Request) (*Chain, *tmrpc.RPCError) {
+	chainID := r.Header.Get(CHAIN_ID_HEADER)
+	chain, ok := lookupChain(chainID)
+	if !ok {
+		return nil, &tmrpc.RPCError{Code: RPC_INVALID_REQUEST, Message: "Invalid Request", Data: fmt.Sprintf("unknown chain %q", chainID)}
+	}
+	return chain, nil
+}
+
+// call answers request with the result of its method on chain
+func (j *jsonRPC) call(ctx context.Context, chain *Chain, request *rpcRequest) *rpcResponse {
+	if request.JSONRPC != "2.0" {
+		return rpcError(request.ID, RPC_INVALID_REQUEST, "Invalid Request", "jsonrpc must be 2.0")
+	}
+	names, ok := RPC_PARAMS[request.Method]
+	if !ok {
+		return rpcError(request.ID, RPC_METHOD_NOT_FOUND, "Method not found", "")
+	}
+	params, err := rpcParams(names, request.Params)
+	if err != nil {
+		return rpcError(request.ID, RPC_INVALID_PARAMS, "Invalid params", err.Error())
+	}
+
+	var result interface{}
+	switch request.Method {
+	case "abci_info", "status":
+		// passed through from the node, so they are the ones the node answers
+		resp, err := chain.RPC.Do(ctx, request.Method, nil)
+		if err != nil {
+			return rpcError(request.ID, RPC_INTERNAL_ERROR, "Internal error", err.Error())
+		}
+		return &rpcResponse{JSONRPC: "2.0", ID: request.ID, Result: resp.Result, Error: resp.Error}
+	case "block":
+		result, err = j.block(ctx, chain, params["height"])
+	case "validators":
+		result, err = j.validators(ctx, chain, params["height"], params["page"], params["per_page"])
+	}
+	if err != nil {
+		return rpcError(request.ID, RPC_INTERNAL_ERROR, "Internal error", status.Convert(err).Message())
+	}
+	bz, err := tmjson.Marshal(result)
+	if err != nil {
+		return rpcError(request.ID, RPC_INTERNAL_ERROR, "Internal error", err.Error())
+	}
+	return &rpcResponse{JSONRPC: "2.0", ID: request.ID, Result: bz}
+}
+
+// rpcParams decodes the integer params of a request, given by name in an object or by position in an array.
+// Tendermint writes 64-bit integers as strings, so both strings and numbers are accepted.
+func rpcParams(names []string, raw json.RawMessage) (map[string]int64, error) {
+	byName := make(map[string]json.RawMessage)
+	trimmed := strings.TrimSpace(string(raw))
+	switch {
+	case trimmed == "" || trimmed == "null":
+	case trimmed[0] == '[':
+		var positional []json.RawMessage
+		if err := json.Unmarshal(raw, &positional); err != nil {
+			return nil, err
+		}
+		if len(positional) > len(names) {
+			return nil, fmt.Errorf("expected at most %d params, got %d", len(names), len(positional))
+		}
+		for i, value := range positional {
+			byName[names[i]] = value
+		}
+	default:
+		if err := json.Unmarshal(raw, &byName); err != nil {
+			return nil, err
+		}
+	}
+
+	params := make(map[string]int64, len(byName))
+	for _, name := range names {
+		value, ok := byName[name]
+		if !ok || string(value) == "null" {
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			s = string(value)
+		}
+		if s == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %s", name, value)
+		}
+		params[name] = n
+	}
+	return params, nil
+}
+
+// rpcError returns the error response of a request
+func rpcError(id json.RawMessage, code int, message, data string) *rpcResponse {
+	return &rpcResponse{JSONRPC: "2.0", ID: id, Error: &tmrpc.RPCError{Code: code, Message: message, Data: data}}
+}
+
+// writeRPC writes the JSON-RPC response or responses v with the HTTP status code
+func writeRPC(w http.ResponseWriter, code int, v interface{}) {
+	bz, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(bz)
+}
+
+// failover calls call with the upstreams of chain in turn, GRPCAddress then the quorum upstreams, moving to the
+// next one while they are unavailable
+func (j *jsonRPC) failover(ctx context.Context, chain *Chain, call func(client tmservice.ServiceClient) error) error {
+	var err error
+	for _, address := range append([]string{chain.GRPCAddress}, chain.QuorumUpstreams...) {
+		client, closeConn, dialErr := j.dial(address)
+		if dialErr != nil {
+			err = dialErr
+			continue
+		}
+		err = call(client)
+		closeConn()
+		if code := status.Code(err); (code != codes.Unavailable && code != codes.DeadlineExceeded) || ctx.Err() != nil {
+			return err
+		}
+		logf("warn", "upstream %s of chain %s failed, trying the next one: %v", address, chain.ChainID, err)
+	}
+	return err
+}
+
+// block returns the block at height like the tendermint block method, the latest one when height is zero.
+// The latest block pushed by the event source is served without an upstream call.
+func (j *jsonRPC) block(ctx context.Context, chain *Chain, height int64) (*coretypes.ResultBlock, error) {
+	blockID, block := chain.Events.latest()
+	if block == nil || (height != 0 && height != block.Header.Height) {
+		err := j.failover(ctx, chain, func(client tmservice.ServiceClient) error {
+			if height == 0 {
+				latest, err := client.GetLatestBlock(ctx, &tmservice.GetLatestBlockRequest{})
+				if err != nil {
+					return err
+				}
+				blockID, block = latest.BlockId, latest.Block
+				return nil
+			}
+			resp, err := client.GetBlockByHeight(ctx, &tmservice.GetBlockByHeightRequest{Height: height})
+			if err != nil {
+				return err
+			}
+			blockID, block = resp.BlockId, resp.Block
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resultBlock(blockID, block)
+}
+
+// resultBlock converts an upstream block into the result of the tendermint block method
+func resultBlock(blockID *tmproto.BlockID, block *tmproto.Block) (*coretypes.ResultBlock, error) {
+	b, err := tmtypes.BlockFromProto(block)
+	if err != nil {
+		return nil, err
+	}
+	ans := &coretypes.ResultBlock{Block: b}
+	if blockID != nil {
+		id, err := tmtypes.BlockIDFromProto(blockID)
+		if err != nil {
+			return nil, err
+		}
+		ans.BlockID = *id
+	}
+	return ans, nil
+}
+
+// validators returns a page of the validator-set at height like the tendermint validators method, the latest one
+// when height is zero. Pages count from 1 and hold DEFAULT_PER_PAGE validators when perPage is zero.
+func (j *jsonRPC) validators(ctx context.Context, chain *Chain, height, page, perPage int64) (*coretypes.ResultValidators, error) {
+	var blockHeight int64
+	var all []*tmservice.Validator
+	err := j.failover(ctx, chain, func(client tmservice.ServiceClient) error {
+		var err error
+		blockHeight, all, err = completeValidatorSet(ctx, client, height)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	set, err := chain.tmValidatorSet(all)
+	if err != nil {
+		return nil, err
+	}
+
+	if perPage <= 0 {
+		perPage = DEFAULT_PER_PAGE
+	} else if perPage > MAX_PER_PAGE {
+		perPage = MAX_PER_PAGE
+	}
+	total := int64(len(set.Validators))
+	pages := (total + perPage - 1) / perPage
+	if pages == 0 {
+		pages = 1
+	}
+	if page == 0 {
+		page = 1
+	}
+	if page < 1 || page > pages {
+		return nil, fmt.Errorf("page should be within [1, %d] range, given %d", pages, page)
+	}
+	start := (page - 1) * perPage
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+	return &coretypes.ResultValidators{
+		BlockHeight: blockHeight,
+		Validators:  set.Validators[start:end],
+		Count:       int(end - start),
+		Total:       int(total),
+	}, nil
+}
@@ -0,0 +1,216 @@
+// This file contains tests for the tendermint JSON-RPC front end.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"grpc_server4/tmrpc"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+// testJSONRPC serves the JSON-RPC front end of an osmosis-1 chain whose GRPCAddress cannot be dialed, its quorum
+// upstream serving chain, and whose node answers abci_info
+func testJSONRPC(t *testing.T, chain *testChain) *httptest.Server {
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req tmrpc.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":{"response":{"data":"osmosis","last_block_height":"3"}}}`, req.ID)
+	}))
+	t.Cleanup(node.Close)
+	config := testChainConfigs[0]
+	config.GRPCAddress, config.RPCAddress, config.QuorumUpstreams = "primary", node.URL, []string{"backup"}
+	if err := initChains(&Config{Chains: []ChainConfig{config}}); err != nil {
+		t.Fatal(err)
+	}
+
+	j := &jsonRPC{dial: func(address string) (tmservice.ServiceClient, func(), error) {
+		if address != "backup" {
+			return nil, nil, fmt.Errorf("connection refused")
+		}
+		return chain.service(), func() {}, nil
+	}}
+	server := httptest.NewServer(j)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// rpcGet GETs the JSON-RPC URI and decodes its response
+func rpcGet(t *testing.T, url string) (int, *rpcResponse) {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var ans rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ans); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, &ans
+}
+
+// rpcPost POSTs the JSON-RPC body and returns the response body
+func rpcPost(t *testing.T, url, body string, header http.Header) []byte {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name := range header {
+		req.Header.Set(name, header.Get(name))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	bz, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bz
+}
+
+// TestJSONRPCBlock tests that blocks are served like tendermint does, from the upstream failed over to and from the
+// latest block of the event source
+func TestJSONRPCBlock(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+	defer InitCcontext()
+
+	chain := newTestChain(t, 4, 3)
+	server := testJSONRPC(t, chain)
+
+	for _, query := range []string{"height=2", `height="2"`} {
+		code, resp := rpcGet(t, server.URL+"/block?"+query)
+		if code != http.StatusOK || resp.Error != nil || string(resp.ID) != RPC_URI_ID {
+			t.Fatalf("%s: unexpected response %d %v", query, code, resp.Error)
+		}
+		var block coretypes.ResultBlock
+		if err := tmjson.Unmarshal(resp.Result, &block); err != nil {
+			t.Fatal(err)
+		}
+		if block.Block.Height != 2 || fmt.Sprintf("%X", block.BlockID.Hash) != fmt.Sprintf("%X", chain.ids[2].Hash) {
+			t.Errorf("%s: expected block 2, got %d %X", query, block.Block.Height, block.BlockID.Hash)
+		}
+		if block.Block.Hash().String() != block.BlockID.Hash.String() {
+			t.Errorf("%s: the block does not hash to its id", query)
+		}
+	}
+
+	// the latest block is served from the event source, the upstream having none at height 0
+	DefaultChain.Events.mtx.Lock()
+	DefaultChain.Events.blockID, DefaultChain.Events.block = chain.ids[3], chain.blocks[3]
+	DefaultChain.Events.mtx.Unlock()
+	code, resp := rpcGet(t, server.URL+"/block")
+	var block coretypes.ResultBlock
+	if code != http.StatusOK || resp.Error != nil || tmjson.Unmarshal(resp.Result, &block) != nil || block.Block.Height != 3 {
+		t.Errorf("expected the latest block 3, got %d %v", code, resp.Error)
+	}
+
+	code, resp = rpcGet(t, server.URL+"/block?height=9")
+	if code != http.StatusInternalServerError || resp.Error == nil || resp.Error.Code != RPC_INTERNAL_ERROR || !strings.Contains(resp.Error.Data, "no block at height 9") {
+		t.Errorf("expected the upstream error, got %d %v", code, resp.Error)
+	}
+	code, resp = rpcGet(t, server.URL+"/block?height=two")
+	if code != http.StatusInternalServerError || resp.Error == nil || resp.Error.Code != RPC_INVALID_PARAMS {
+		t.Errorf("expected invalid params, got %d %v", code, resp.Error)
+	}
+}
+
+// TestJSONRPCValidators tests that validator-sets are paged like tendermint does
+func TestJSONRPCValidators(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+	defer InitCcontext()
+
+	chain := newTestChain(t, 4, 3)
+	server := testJSONRPC(t, chain)
+
+	code, resp := rpcGet(t, server.URL+"/validators?height=2&page=2&per_page=3")
+	if code != http.StatusOK || resp.Error != nil {
+		t.Fatalf("unexpected response %d %v", code, resp.Error)
+	}
+	var validators coretypes.ResultValidators
+	if err := tmjson.Unmarshal(resp.Result, &validators); err != nil {
+		t.Fatal(err)
+	}
+	if validators.BlockHeight != 2 || validators.Count != 1 || validators.Total != 4 {
+		t.Fatalf("expected the last of 4 validators at height 2, got %+v", validators)
+	}
+	if validators.Validators[0].Address.String() != chain.set.Validators[3].Address.String() || validators.Validators[0].VotingPower != chain.set.Validators[3].VotingPower {
+		t.Errorf("expected the fourth validator of the set, got %v", validators.Validators[0])
+	}
+
+	code, resp = rpcGet(t, server.URL+"/validators?page=3&per_page=3")
+	if code != http.StatusInternalServerError || resp.Error == nil || !strings.Contains(resp.Error.Data, "page should be within [1, 2] range") {
+		t.Errorf("expected the page out of range, got %d %v", code, resp.Error)
+	}
+}
+
+// TestJSONRPCPost tests the POSTed requests: single, batched with a notification, with positional params, passed
+// through to the node, and failing
+func TestJSONRPCPost(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+	defer InitCcontext()
+
+	chain := newTestChain(t, 4, 3)
+	server := testJSONRPC(t, chain)
+
+	var single rpcResponse
+	if err := json.Unmarshal(rpcPost(t, server.URL, `{"jsonrpc":"2.0","id":"abc","method":"abci_info","params":{}}`, nil), &single); err != nil {
+		t.Fatal(err)
+	}
+	if string(single.ID) != `"abc"` || single.Error != nil || !strings.Contains(string(single.Result), `"last_block_height": "3"`) {
+		t.Errorf("expected the abci_info of the node, got %s %v", single.Result, single.Error)
+	}
+
+	var batch []rpcResponse
+	body := `[
+		{"jsonrpc":"2.0","id":1,"method":"block","params":["1"]},
+		{"jsonrpc":"2.0","method":"status"},
+		{"jsonrpc":"2.0","id":2,"method":"validators","params":{"height":1,"per_page":"2"}},
+		{"jsonrpc":"2.0","id":3,"method":"net_info"}
+	]`
+	if err := json.Unmarshal(rpcPost(t, server.URL, body, nil), &batch); err != nil {
+		t.Fatal(err)
+	}
+	if len(batch) != 3 {
+		t.Fatalf("expected the notification unanswered, got %d responses", len(batch))
+	}
+	var block coretypes.ResultBlock
+	if string(batch[0].ID) != "1" || tmjson.Unmarshal(batch[0].Result, &block) != nil || block.Block.Height != 1 {
+		t.Errorf("expected block 1, got %s %v", batch[0].Result, batch[0].Error)
+	}
+	var validators coretypes.ResultValidators
+	if string(batch[1].ID) != "2" || tmjson.Unmarshal(batch[1].Result, &validators) != nil || validators.Count != 2 || validators.Total != 4 {
+		t.Errorf("expected 2 of 4 validators, got %s %v", batch[1].Result, batch[1].Error)
+	}
+	if batch[2].Error == nil || batch[2].Error.Code != RPC_METHOD_NOT_FOUND {
+		t.Errorf("expected net_info not found, got %v", batch[2].Error)
+	}
+
+	if err := json.Unmarshal(rpcPost(t, server.URL, `{"jsonrpc":"2.0","id":1,"method":"block"`, nil), &single); err != nil {
+		t.Fatal(err)
+	}
+	if single.Error == nil || single.Error.Code != RPC_PARSE_ERROR {
+		t.Errorf("expected a parse error, got %v", single.Error)
+	}
+
+	header := http.Header{}
+	header.Set(CHAIN_ID_HEADER, "cosmoshub-4")
+	if err := json.Unmarshal(rpcPost(t, server.URL, `{"jsonrpc":"2.0","id":1,"method":"status"}`, header), &single); err != nil {
+		t.Fatal(err)
+	}
+	if single.Error == nil || single.Error.Code != RPC_INVALID_REQUEST || !strings.Contains(single.Error.Data, "cosmoshub-4") {
+		t.Errorf("expected the unknown chain rejected, got %v", single.Error)
+	}
+}
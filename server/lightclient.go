@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	tmmath "github.com/tendermint/tendermint/libs/math"
+	"github.com/tendermint/tendermint/light"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// LIGHT_TRUSTED_BLOCKS is the number of verified light blocks the light client keeps as trust points
+const LIGHT_TRUSTED_BLOCKS = 1000
+
+// LIGHT_DEFAULT_TRUSTING_PERIOD is the trusting period when the config does not set one
+const LIGHT_DEFAULT_TRUSTING_PERIOD = 168 * time.Hour
+
+// LIGHT_MAX_CLOCK_DRIFT is how far ahead of the local clock a header time may be
+const LIGHT_MAX_CLOCK_DRIFT = 10 * time.Second
+
+// lightClient is a tendermint light client reading light blocks through the tmservice block and validator-set calls.
+// From the configured trusted header it verifies later headers by skipping verification, bisecting when less than
+// the trust level of a trusted validator-set signed, and earlier headers by following their LastBlockId hashes.
+type lightClient struct {
+	// mtx guards trusted, heights and generation, it is never held across an upstream call
+	mtx            sync.Mutex
+	chainID        string
+	trustHeight    int64
+	trustHash      []byte
+	trustingPeriod time.Duration
+	trustLevel     tmmath.Fraction
+	// trusted are the verified light blocks by height, heights holds their heights in ascending order
+	trusted map[int64]*tmtypes.LightBlock
+	heights []int64
+	// generation counts the flushes, a verification started before a flush does not store its light blocks
+	generation int64
+	// latestCommit returns the commit for a height whose next block is not produced yet
+	latestCommit func(ctx context.Context, height int64) (*tmproto.Commit, error)
+	now          func() time.Time
+}
+
+// newLightClient returns a light client for chainID trusting the configured header, or nil when none is configured
+func newLightClient(chainID string, conf LightClientConfig) (*lightClient, error) {
+	if conf.TrustedHeight == 0 && conf.TrustedHash == "" {
+		return nil, nil
+	}
+	if conf.TrustedHeight <= 0 {
+		return nil, fmt.Errorf("invalid light client trusted height %d", conf.TrustedHeight)
+	}
+	hash, err := hex.DecodeString(conf.TrustedHash)
+	if err != nil || len(hash) != 32 {
+		return nil, fmt.Errorf("invalid light client trusted hash %q", conf.TrustedHash)
+	}
+	trustingPeriod := conf.TrustingPeriod
+	if trustingPeriod == 0 {
+		trustingPeriod = LIGHT_DEFAULT_TRUSTING_PERIOD
+	}
+	return &lightClient{
+		chainID:        chainID,
+		trustHeight:    conf.TrustedHeight,
+		trustHash:      hash,
+		trustingPeriod: trustingPeriod,
+		trustLevel:     light.DefaultTrustLevel,
+		trusted:        make(map[int64]*tmtypes.LightBlock),
+		latestCommit:   rpcCommit,
+		now:            time.Now,
+	}, nil
+}
+
+// lightVerify verifies header with the light client of the chain, reporting why it is not verified otherwise
+func lightVerify(ctx context.Context, client tmservice.ServiceClient, header tmproto.Header) (bool, string) {
+	lc := chainFromContext(ctx).LightClient
+	if lc == nil {
+		return false, "light client is not configured"
+	}
+	if err := lc.verifyHeader(ctx, client, header); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// rpcCommit reads the commit for height from the tendermint RPC, which also serves the commit of the latest block
+func rpcCommit(ctx context.Context, height int64) (*tmproto.Commit, error) {
+	res, err := chainFromContext(ctx).RPC.Commit(ctx, &height)
+	if err != nil {
+		return nil, err
+	}
+	return res.Commit.ToProto(), nil
+}
+
+// verifyHeader checks that header is the one the light client verifies at its height
+func (c *lightClient) verifyHeader(ctx context.Context, client tmservice.ServiceClient, header tmproto.Header) error {
+	h, err := tmtypes.HeaderFromProto(&header)
+	if err != nil {
+		return fmt.Errorf("invalid header: %w", err)
+	}
+	trusted, lowest, generation := c.trustPoint(h.Height)
+	if trusted == nil && lowest == nil {
+		lb, err := c.initialize(ctx, client)
+		if err != nil {
+			return err
+		}
+		c.store(generation, lb)
+		if lb.Height <= h.Height {
+			trusted = lb
+		} else {
+			lowest = lb.Header
+		}
+	}
+	var verified *tmtypes.Header
+	switch {
+	case trusted != nil && trusted.Height == h.Height:
+		verified = trusted.Header
+	case trusted != nil:
+		lb, err := c.verifyForward(ctx, client, trusted, h.Height, generation)
+		if err != nil {
+			return err
+		}
+		verified = lb.Header
+	default:
+		verified, err = c.verifyBackward(ctx, client, lowest, h.Height)
+		if err != nil {
+			return err
+		}
+	}
+	if !bytes.Equal(h.Hash(), verified.Hash()) {
+		return fmt.Errorf("header hash %X at height %d is not the verified hash %X", h.Hash(), h.Height, verified.Hash())
+	}
+	return nil
+}
+
+// trustPoint returns the closest verified light block at or below height, or else the lowest verified header, with
+// the generation they were verified in. Both are nil before the first verification.
+func (c *lightClient) trustPoint(height int64) (*tmtypes.LightBlock, *tmtypes.Header, int64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if len(c.heights) == 0 {
+		return nil, nil, c.generation
+	}
+	i := sort.Search(len(c.heights), func(i int) bool { return c.heights[i] > height })
+	if i > 0 {
+		return c.trusted[c.heights[i-1]], nil, c.generation
+	}
+	return nil, c.trusted[c.heights[0]].Header, c.generation
+}
+
+// initialize fetches the light block at the trusted height and checks it against the trusted hash
+func (c *lightClient) initialize(ctx context.Context, client tmservice.ServiceClient) (*tmtypes.LightBlock, error) {
+	lb, err := c.lightBlock(ctx, client, c.trustHeight)
+	if err != nil {
+		return nil, err
+	}
+	if err := lb.ValidateBasic(c.chainID); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(lb.Hash(), c.trustHash) {
+		return nil, fmt.Errorf("trusted header hash at height %d is %X, expected %X", c.trustHeight, lb.Hash(), c.trustHash)
+	}
+	if light.HeaderExpired(lb.SignedHeader, c.trustingPeriod, c.now()) {
+		return nil, fmt.Errorf("trusted header at height %d is older than the trusting period %s", c.trustHeight, c.trustingPeriod)
+	}
+	if err := lb.ValidatorSet.VerifyCommitLight(c.chainID, lb.Commit.BlockID, lb.Height, lb.Commit); err != nil {
+		return nil, fmt.Errorf("invalid commit for the trusted header: %w", err)
+	}
+	return lb, nil
+}
+
+// verifyForward verifies the light block at height from a trusted one below it. Every light block verified on
+// the way is stored unless the light client was flushed since generation, so later heights start from the closest one.
+func (c *lightClient) verifyForward(ctx context.Context, client tmservice.ServiceClient, trusted *tmtypes.LightBlock, height, generation int64) (*tmtypes.LightBlock, error) {
+	target, err := c.lightBlock(ctx, client, height)
+	if err != nil {
+		return nil, err
+	}
+	pivot := target
+	for {
+		err := light.Verify(trusted.SignedHeader, trusted.ValidatorSet, pivot.SignedHeader, pivot.ValidatorSet,
+			c.trustingPeriod, c.now(), LIGHT_MAX_CLOCK_DRIFT, c.trustLevel)
+		var cantTrust light.ErrNewValSetCantBeTrusted
+		switch {
+		case err == nil:
+			c.store(generation, pivot)
+			if pivot.Height == height {
+				return pivot, nil
+			}
+			trusted, pivot = pivot, target
+		case errors.As(err, &cantTrust):
+			// too few of the trusted validators signed the pivot, try halfway
+			pivot, err = c.lightBlock(ctx, client, (trusted.Height+pivot.Height)/2)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("header at height %d does not verify from height %d: %w", pivot.Height, trusted.Height, err)
+		}
+	}
+}
+
+// verifyBackward verifies the header at height below a trusted one by following the LastBlockId hashes down to it
+func (c *lightClient) verifyBackward(ctx context.Context, client tmservice.ServiceClient, trusted *tmtypes.Header, height int64) (*tmtypes.Header, error) {
+	if trusted.Height-height > MAX_BLOCK_RANGE {
+		return nil, fmt.Errorf("height %d is more than %d blocks below the trusted height %d", height, MAX_BLOCK_RANGE, trusted.Height)
+	}
+	for h := trusted.Height - 1; h >= height; h-- {
+		block, err := client.GetBlockByHeight(ctx, &tmservice.GetBlockByHeightRequest{Height: h})
+		if err != nil {
+			return nil, err
+		}
+		untrusted, err := tmtypes.HeaderFromProto(&block.Block.Header)
+		if err != nil {
+			return nil, err
+		}
+		if err := light.VerifyBackwards(&untrusted, trusted); err != nil {
+			return nil, err
+		}
+		trusted = &untrusted
+	}
+	return trusted, nil
+}
+
+// lightBlock assembles the light block at height: its header, the commit carried by the next block, and its
+// validator-set. The commit of the latest block comes from latestCommit.
+func (c *lightClient) lightBlock(ctx context.Context, client tmservice.ServiceClient, height int64) (*tmtypes.LightBlock, error) {
+	block, err := client.GetBlockByHeight(ctx, &tmservice.GetBlockByHeightRequest{Height: height})
+	if err != nil {
+		return nil, err
+	}
+	header, err := tmtypes.HeaderFromProto(&block.Block.Header)
+	if err != nil {
+		return nil, err
+	}
+	var pc *tmproto.Commit
+	if next, err := client.GetBlockByHeight(ctx, &tmservice.GetBlockByHeightRequest{Height: height + 1}); err == nil {
+		pc = next.Block.LastCommit
+	} else if c.latestCommit != nil {
+		if pc, err = c.latestCommit(ctx, height); err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, err
+	}
+	commit, err := tmtypes.CommitFromProto(pc)
+	if err != nil {
+		return nil, err
+	}
+	set, err := newValidatorSets(client).tmAt(ctx, block.Block.Header)
+	if err != nil {
+		return nil, err
+	}
+	return &tmtypes.LightBlock{
+		SignedHeader: &tmtypes.SignedHeader{Header: &header, Commit: commit},
+		ValidatorSet: set,
+	}, nil
+}
+
+// size returns the number of verified light blocks kept
+func (c *lightClient) size() int64 {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return int64(len(c.heights))
+}
+
+// flush drops every verified light block, the next verification starts again from the configured trusted header.
+// It returns the number of light blocks dropped.
+func (c *lightClient) flush() int64 {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	n := int64(len(c.heights))
+	c.trusted, c.heights = make(map[int64]*tmtypes.LightBlock), nil
+	c.generation++
+	return n
+}
+
+// store keeps a light block verified in generation, dropping the lowest ones beyond LIGHT_TRUSTED_BLOCKS
+func (c *lightClient) store(generation int64, lb *tmtypes.LightBlock) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if generation != c.generation {
+		return
+	}
+	if _, ok := c.trusted[lb.Height]; ok {
+		return
+	}
+	c.trusted[lb.Height] = lb
+	i := sort.Search(len(c.heights), func(i int) bool { return c.heights[i] > lb.Height })
+	c.heights = append(c.heights, 0)
+	copy(c.heights[i+1:], c.heights[i:])
+	c.heights[i] = lb.Height
+	for len(c.heights) > LIGHT_TRUSTED_BLOCKS {
+		delete(c.trusted, c.heights[0])
+		c.heights = c.heights[1:]
+	}
+}
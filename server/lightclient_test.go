@@ -0,0 +1,242 @@
+// This file contains tests for the light client behind the verified flag of GetLatestBlock and GetBlockByHeight.
+//
+// The chains are built by newTestChain and newRotatingTestChain, so every header and commit is genuine.
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"google.golang.org/grpc"
+)
+
+// testLightClient returns a light client trusting the header of chain at height, at a time shortly after the chain
+func testLightClient(t *testing.T, chain *testChain, height int64) *lightClient {
+	c, err := newLightClient(TEST_CHAIN_ID, LightClientConfig{
+		TrustedHeight: height,
+		TrustedHash:   fmt.Sprintf("%X", chain.ids[height].Hash),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.latestCommit = nil
+	c.now = func() time.Time { return time.Unix(1600000000+1000, 0) }
+	return c
+}
+
+// stalledService stalls the block fetches of a fake validator service until release is closed, signalling fetching
+// on the first one
+type stalledService struct {
+	*fakeValidatorService
+	once     sync.Once
+	fetching chan struct{}
+	release  chan struct{}
+}
+
+func newStalledService(f *fakeValidatorService) *stalledService {
+	return &stalledService{fakeValidatorService: f, fetching: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (s *stalledService) GetBlockByHeight(ctx context.Context, in *tmservice.GetBlockByHeightRequest, opts ...grpc.CallOption) (*tmservice.GetBlockByHeightResponse, error) {
+	s.once.Do(func() { close(s.fetching) })
+	<-s.release
+	return s.fakeValidatorService.GetBlockByHeight(ctx, in, opts...)
+}
+
+// TestNewLightClient tests that the light client is disabled without a trusted header and rejects an invalid one
+func TestNewLightClient(t *testing.T) {
+	if c, err := newLightClient(TEST_CHAIN_ID, LightClientConfig{}); c != nil || err != nil {
+		t.Errorf("expected no light client without a trusted header, got %v %v", c, err)
+	}
+	if _, err := newLightClient(TEST_CHAIN_ID, LightClientConfig{TrustedHeight: 10, TrustedHash: "ABCD"}); err == nil {
+		t.Error("expected an error for a short trusted hash")
+	}
+	c, err := newLightClient(TEST_CHAIN_ID, LightClientConfig{TrustedHeight: 10, TrustedHash: fmt.Sprintf("%064X", 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.trustingPeriod != LIGHT_DEFAULT_TRUSTING_PERIOD {
+		t.Errorf("unexpected trusting period %s", c.trustingPeriod)
+	}
+}
+
+// TestLightClientSkipping tests that a later header is verified in one step when the validator-set does not change
+func TestLightClientSkipping(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	chain := newTestChain(t, 4, 20)
+	c := testLightClient(t, chain, 2)
+	client := chain.service()
+	if err := c.verifyHeader(context.Background(), client, chain.blocks[15].Header); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.heights) != 2 || c.heights[0] != 2 || c.heights[1] != 15 {
+		t.Errorf("expected to skip from 2 to 15, trusted %v", c.heights)
+	}
+	// already verified headers are not fetched again
+	requested := len(client.requested)
+	if err := c.verifyHeader(context.Background(), client, chain.blocks[15].Header); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.requested) != requested {
+		t.Errorf("expected no fetch for a verified header")
+	}
+}
+
+// TestLightClientBisection tests that verification bisects when too few of the trusted validators remain
+func TestLightClientBisection(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	// the validator-set is replaced every 5 blocks, so only adjacent headers link two sets
+	chain := newRotatingTestChain(t, 4, 21, 5)
+	c := testLightClient(t, chain, 1)
+	if err := c.verifyHeader(context.Background(), chain.service(), chain.blocks[20].Header); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.heights) <= 2 || c.heights[len(c.heights)-1] != 20 {
+		t.Errorf("expected intermediate trusted headers, trusted %v", c.heights)
+	}
+	for _, h := range []int64{5, 6, 10, 11, 15, 16} {
+		if _, ok := c.trusted[h]; !ok {
+			t.Errorf("expected the validator-set change at %d to be verified, trusted %v", h, c.heights)
+		}
+	}
+}
+
+// TestLightClientBackward tests that a header below the trusted one is verified through the LastBlockId hashes
+func TestLightClientBackward(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	chain := newTestChain(t, 4, 12)
+	c := testLightClient(t, chain, 10)
+	if err := c.verifyHeader(context.Background(), chain.service(), chain.blocks[3].Header); err != nil {
+		t.Fatal(err)
+	}
+
+	forged := chain.blocks[3].Header
+	forged.AppHash = make([]byte, 32)
+	if err := c.verifyHeader(context.Background(), chain.service(), forged); err == nil {
+		t.Error("expected a forged header below the trusted one to fail")
+	}
+}
+
+// TestLightClientForged tests that headers which are not the verified ones fail
+func TestLightClientForged(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	chain := newTestChain(t, 4, 20)
+	c := testLightClient(t, chain, 2)
+	forged := chain.blocks[15].Header
+	forged.AppHash = make([]byte, 32)
+	if err := c.verifyHeader(context.Background(), chain.service(), forged); err == nil {
+		t.Error("expected a forged header to fail")
+	}
+
+	// an upstream forks the chain at 15 with validators outside the trusted set, every header until the fork is genuine
+	other := newRotatingTestChain(t, 4, 16, 14)
+	c = testLightClient(t, chain, 2)
+	client := chain.service()
+	client.blocks = make(map[int64]*tmproto.Block)
+	for h := int64(1); h <= 16; h++ {
+		client.blocks[h] = chain.blocks[h]
+	}
+	for h := int64(15); h <= 16; h++ {
+		client.blocks[h] = other.blocks[h]
+		client.sets[h] = tmserviceValidators(other.sets[h])
+	}
+	if err := c.verifyHeader(context.Background(), client, other.blocks[15].Header); err == nil {
+		t.Error("expected a header of another validator-set to fail")
+	}
+}
+
+// TestLightClientTrustedHeader tests that a wrong or expired trusted header is rejected
+func TestLightClientTrustedHeader(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	chain := newTestChain(t, 4, 5)
+	c := testLightClient(t, chain, 2)
+	c.trustHash = chain.ids[3].Hash
+	if err := c.verifyHeader(context.Background(), chain.service(), chain.blocks[4].Header); err == nil {
+		t.Error("expected a wrong trusted hash to fail")
+	}
+
+	c = testLightClient(t, chain, 2)
+	c.now = func() time.Time { return time.Unix(1600000000, 0).Add(LIGHT_DEFAULT_TRUSTING_PERIOD + time.Hour) }
+	if err := c.verifyHeader(context.Background(), chain.service(), chain.blocks[4].Header); err == nil {
+		t.Error("expected an expired trusted header to fail")
+	}
+}
+
+// TestLightClientLatest tests that the latest header is verified with the commit from latestCommit
+func TestLightClientLatest(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	chain := newTestChain(t, 4, 11)
+	client := chain.service()
+	// block 11 is not produced yet, only its LastCommit is known
+	client.blocks = make(map[int64]*tmproto.Block)
+	for h := int64(1); h <= 10; h++ {
+		client.blocks[h] = chain.blocks[h]
+	}
+
+	c := testLightClient(t, chain, 2)
+	if err := c.verifyHeader(context.Background(), client, chain.blocks[10].Header); err == nil {
+		t.Error("expected the latest header to fail without a commit")
+	}
+
+	c = testLightClient(t, chain, 2)
+	c.latestCommit = func(ctx context.Context, height int64) (*tmproto.Commit, error) {
+		if height != 10 {
+			return nil, fmt.Errorf("unexpected commit height %d", height)
+		}
+		return chain.blocks[11].LastCommit, nil
+	}
+	if err := c.verifyHeader(context.Background(), client, chain.blocks[10].Header); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestLightClientConcurrent tests that a verification stalled upstream does not hold up one of a verified header
+func TestLightClientConcurrent(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	chain := newTestChain(t, 4, 20)
+	c := testLightClient(t, chain, 2)
+	if err := c.verifyHeader(context.Background(), chain.service(), chain.blocks[15].Header); err != nil {
+		t.Fatal(err)
+	}
+
+	stalled := newStalledService(chain.service())
+	done := make(chan error)
+	go func() { done <- c.verifyHeader(context.Background(), stalled, chain.blocks[18].Header) }()
+	<-stalled.fetching
+	verified := make(chan error)
+	go func() { verified <- c.verifyHeader(context.Background(), chain.service(), chain.blocks[15].Header) }()
+	select {
+	case err := <-verified:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("expected the verified header not to wait for the stalled verification")
+	}
+	close(stalled.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if len(c.heights) != 3 || c.heights[2] != 18 {
+		t.Errorf("expected 18 to be verified from 15, trusted %v", c.heights)
+	}
+}
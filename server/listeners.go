@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// DEFAULT_LISTEN is the address GrpcQueryService is served on when the config lists no listeners
+const DEFAULT_LISTEN = "localhost:9090"
+
+// DEFAULT_SOCKET_MODE is the file mode of a unix socket whose config does not set one
+const DEFAULT_SOCKET_MODE = 0o660
+
+// UNIX_SCHEME and TCP_SCHEME prefix the address of a unix socket and of a TCP listener, a bare address is TCP
+const (
+	UNIX_SCHEME = "unix://"
+	TCP_SCHEME  = "tcp://"
+)
+
+// SNIFF_TIMEOUT bounds the wait for the first bytes telling a gRPC connection from an HTTP one
+const SNIFF_TIMEOUT = 10 * time.Second
+
+// HTTP2_PREFACE opens every HTTP/2 connection, so every gRPC one
+const HTTP2_PREFACE = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+
+// ListenerConfig is an address the server listens on
+type ListenerConfig struct {
+	// Address is host:port or tcp://host:port for TCP, unix:///path for a unix socket
+	Address string `yaml:"address"`
+	// SocketMode is the octal file mode of a unix socket, DEFAULT_SOCKET_MODE when empty
+	SocketMode string `yaml:"socket_mode"`
+}
+
+// parse returns the network and address of the listener, and the file mode of a unix socket
+func (c ListenerConfig) parse() (network, address string, mode os.FileMode, err error) {
+	mode = DEFAULT_SOCKET_MODE
+	if c.SocketMode != "" {
+		m, err := strconv.ParseUint(c.SocketMode, 8, 32)
+		if err != nil || m > 0o777 {
+			return "", "", 0, fmt.Errorf("invalid socket_mode %q of %s, expected an octal file mode", c.SocketMode, c.Address)
+		}
+		mode = os.FileMode(m)
+	}
+	switch {
+	case strings.HasPrefix(c.Address, UNIX_SCHEME):
+		network, address = "unix", strings.TrimPrefix(c.Address, UNIX_SCHEME)
+	case strings.HasPrefix(c.Address, TCP_SCHEME):
+		network, address = "tcp", strings.TrimPrefix(c.Address, TCP_SCHEME)
+	default:
+		network, address = "tcp", c.Address
+	}
+	if address == "" {
+		return "", "", 0, fmt.Errorf("listener %q has no address", c.Address)
+	}
+	return network, address, mode, nil
+}
+
+// listen listens on the address of the listener. A unix socket left by a previous run is removed first, and the
+// socket file gets the configured mode.
+func (c ListenerConfig) listen() (net.Listener, error) {
+	network, address, mode, err := c.parse()
+	if err != nil {
+		return nil, err
+	}
+	if network == "unix" {
+		if info, err := os.Stat(address); err == nil && info.Mode()&os.ModeSocket != 0 {
+			if err := os.Remove(address); err != nil {
+				return nil, err
+			}
+		}
+	}
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	if network == "unix" {
+		if err := os.Chmod(address, mode); err != nil {
+			listener.Close()
+			return nil, err
+		}
+	}
+	return listener, nil
+}
+
+// listenerConfigs returns the listeners of GrpcQueryService in the config, DEFAULT_LISTEN when it lists none
+func listenerConfigs(config *Config) []ListenerConfig {
+	if len(config.Listeners) == 0 {
+		return []ListenerConfig{{Address: DEFAULT_LISTEN}}
+	}
+	return config.Listeners
+}
+
+// validateListeners checks the addresses and socket modes of the listeners and of the admin listener
+func validateListeners(config *Config) error {
+	listeners := listenerConfigs(config)
+	if config.Admin.Listen != "" {
+		listeners = append(listeners, ListenerConfig{Address: config.Admin.Listen, SocketMode: config.Admin.SocketMode})
+	}
+	seen := make(map[string]bool, len(listeners))
+	for _, l := range listeners {
+		network, address, _, err := l.parse()
+		if err != nil {
+			return err
+		}
+		if seen[network+address] {
+			return fmt.Errorf("%s is listened on twice", l.Address)
+		}
+		seen[network+address] = true
+	}
+	return nil
+}
+
+// httpHandler returns the HTTP handlers served on the listeners of GrpcQueryService next to gRPC: the health check,
+// the GraphQL API, the tendermint JSON-RPC front end and the gRPC-Web calls of grpcServer
+func httpHandler(grpcServer *grpc.Server) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	mux.Handle("/graphql", corsHandler(newGraphQLHandler(&server{}, dialChain)))
+	mux.Handle("/", newJSONRPC())
+	return grpcWebHandler(grpcServer, mux)
+}
+
+// serveSplit serves grpcServer and httpServer on the connections of listener, until one of them fails
+func serveSplit(grpcServer *grpc.Server, httpServer *http.Server, listener net.Listener) error {
+	split := newSplitListener(listener)
+	errs := make(chan error, 3)
+	go func() { errs <- grpcServer.Serve(split.grpc) }()
+	go func() { errs <- httpServer.Serve(split.http) }()
+	go func() { errs <- split.serve() }()
+	return <-errs
+}
+
+// splitListener splits the connections of a listener between gRPC, opening with the HTTP/2 preface, and HTTP/1
+type splitListener struct {
+	root net.Listener
+	grpc *connListener
+	http *connListener
+}
+
+// newSplitListener splits the connections of root, which are only accepted once serve runs
+func newSplitListener(root net.Listener) *splitListener {
+	return &splitListener{
+		root: root,
+		grpc: newConnListener(root.Addr()),
+		http: newConnListener(root.Addr()),
+	}
+}
+
+// serve accepts the connections of the root listener until it fails, then closes the split listeners
+func (s *splitListener) serve() error {
+	for {
+		conn, err := s.root.Accept()
+		if err != nil {
+			s.grpc.close(err)
+			s.http.close(err)
+			return err
+		}
+		go s.dispatch(conn)
+	}
+}
+
+// dispatch reads the first bytes of conn, as long as they match the HTTP/2 preface, to hand it to the gRPC or
+// the HTTP listener with those bytes still to be read
+func (s *splitListener) dispatch(conn net.Conn) {
+	r := bufio.NewReaderSize(conn, len(HTTP2_PREFACE))
+	conn.SetReadDeadline(time.Now().Add(SNIFF_TIMEOUT))
+	isGRPC := true
+	for i := 1; i <= len(HTTP2_PREFACE); i++ {
+		// only blocks for the next byte when the ones read so far match
+		bz, err := r.Peek(i)
+		if err != nil {
+			conn.Close()
+			return
+		}
+		if bz[i-1] != HTTP2_PREFACE[i-1] {
+			isGRPC = false
+			break
+		}
+	}
+	conn.SetReadDeadline(time.Time{})
+	sniffed := &sniffedConn{Conn: conn, r: r}
+	if isGRPC {
+		s.grpc.push(sniffed)
+	} else {
+		s.http.push(sniffed)
+	}
+}
+
+// sniffedConn is a connection whose first bytes were read into r
+type sniffedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *sniffedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// connListener is a listener accepting the connections pushed to it
+type connListener struct {
+	addr  net.Addr
+	conns chan net.Conn
+	once  sync.Once
+	done  chan struct{}
+	err   error
+}
+
+func newConnListener(addr net.Addr) *connListener {
+	return &connListener{addr: addr, conns: make(chan net.Conn), done: make(chan struct{})}
+}
+
+// push hands conn to the next Accept, closing it when the listener is closed
+func (l *connListener) push(conn net.Conn) {
+	select {
+	case l.conns <- conn:
+	case <-l.done:
+		conn.Close()
+	}
+}
+
+// close makes Accept fail with err
+func (l *connListener) close(err error) {
+	l.once.Do(func() {
+		l.err = err
+		close(l.done)
+	})
+}
+
+func (l *connListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, l.err
+	}
+}
+
+func (l *connListener) Close() error {
+	l.close(net.ErrClosed)
+	return nil
+}
+
+func (l *connListener) Addr() net.Addr {
+	return l.addr
+}
@@ -0,0 +1,132 @@
+// This file contains tests for the listeners and the split of their connections between gRPC and HTTP.
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	types "grpc_server4/proto/generated"
+
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"google.golang.org/grpc"
+)
+
+// TestListenerConfigParse tests that listener addresses and socket modes are parsed, and invalid ones rejected
+func TestListenerConfigParse(t *testing.T) {
+	cases := []struct {
+		config  ListenerConfig
+		network string
+		address string
+		mode    os.FileMode
+	}{
+		{ListenerConfig{Address: "0.0.0.0:9090"}, "tcp", "0.0.0.0:9090", DEFAULT_SOCKET_MODE},
+		{ListenerConfig{Address: "tcp://[::]:9090"}, "tcp", "[::]:9090", DEFAULT_SOCKET_MODE},
+		{ListenerConfig{Address: "unix:///run/grpc.sock", SocketMode: "0600"}, "unix", "/run/grpc.sock", 0o600},
+	}
+	for _, c := range cases {
+		network, address, mode, err := c.config.parse()
+		if err != nil {
+			t.Fatalf("%s: %v", c.config.Address, err)
+		}
+		if network != c.network || address != c.address || mode != c.mode {
+			t.Errorf("%s: expected %s %s %o, got %s %s %o", c.config.Address, c.network, c.address, c.mode, network, address, mode)
+		}
+	}
+	for _, config := range []ListenerConfig{{Address: "unix://"}, {Address: ""}, {Address: "unix:///run/grpc.sock", SocketMode: "rw"}} {
+		if _, _, _, err := config.parse(); err == nil {
+			t.Errorf("%+v: expected an error", config)
+		}
+	}
+
+	duplicate := &Config{
+		Listeners: []ListenerConfig{{Address: "localhost:9090"}},
+		Admin:     AdminConfig{Listen: "tcp://localhost:9090"},
+	}
+	if err := validateListeners(duplicate); err == nil {
+		t.Error("expected an error for the admin service on the port of a listener")
+	}
+}
+
+// serveTestSplit serves a GrpcQueryService returning an empty block at every height and the HTTP handlers on
+// listener, and returns the client of the service dialed with dial
+func serveTestSplit(t *testing.T, listener net.Listener, dial func(ctx context.Context, _ string) (net.Conn, error)) types.GrpcQueryServiceClient {
+	grpcServer := grpc.NewServer()
+	types.RegisterGrpcQueryServiceServer(grpcServer, &bigBlockService{resp: &types.GetBlockByHeightResponse{Block: &tmproto.Block{}}})
+	httpServer := &http.Server{Handler: httpHandler(grpcServer)}
+	go serveSplit(grpcServer, httpServer, listener)
+	t.Cleanup(func() {
+		grpcServer.Stop()
+		httpServer.Close()
+		listener.Close()
+	})
+	conn, err := grpc.Dial("split", grpc.WithInsecure(), grpc.WithContextDialer(dial))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return types.NewGrpcQueryServiceClient(conn)
+}
+
+// TestServeSplit tests that gRPC and HTTP are served on the same TCP port
+func TestServeSplit(t *testing.T) {
+	listener, err := ListenerConfig{Address: "tcp://127.0.0.1:0"}.listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	c := serveTestSplit(t, listener, func(ctx context.Context, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, "tcp", address)
+	})
+
+	if _, err := c.GetBlockByHeight(context.Background(), &types.GetBlockByHeightRequest{Height: 1}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Get("http://" + address + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "ok\n" {
+		t.Errorf("expected the health check ok, got %d %q", resp.StatusCode, body)
+	}
+	// the gRPC connection is still served after the HTTP one
+	if _, err := c.GetBlockByHeight(context.Background(), &types.GetBlockByHeightRequest{Height: 2}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestUnixSocket tests that a unix socket gets its mode, replaces the socket left by a previous run and is served
+func TestUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "grpc.sock")
+	config := ListenerConfig{Address: UNIX_SCHEME + path, SocketMode: "0600"}
+	stale, err := config.listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	listener, err := config.listen()
+	if err != nil {
+		t.Fatalf("expected the stale socket replaced, got %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected the socket mode 0600, got %o", info.Mode().Perm())
+	}
+	c := serveTestSplit(t, listener, func(ctx context.Context, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, "unix", path)
+	})
+	if _, err := c.GetBlockByHeight(context.Background(), &types.GetBlockByHeightRequest{Height: 1}); err != nil {
+		t.Fatal(err)
+	}
+}
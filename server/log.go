@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// LOG_LEVELS are the log levels by name, from the most verbose
+var LOG_LEVELS = []string{"debug", "info", "warn", "error"}
+
+// DEFAULT_LOG_LEVEL is the log level when the config does not set one
+const DEFAULT_LOG_LEVEL = "info"
+
+// logLevel is the index in LOG_LEVELS of the least severe level logged
+var logLevel int32 = 1
+
+// logLevelIndex returns the index in LOG_LEVELS of a level name
+func logLevelIndex(level string) (int32, error) {
+	for i, name := range LOG_LEVELS {
+		if name == level {
+			return int32(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q, expected one of %s", level, strings.Join(LOG_LEVELS, ", "))
+}
+
+// setLogLevel changes the log level, returning the previous one
+func setLogLevel(level string) (string, error) {
+	i, err := logLevelIndex(level)
+	if err != nil {
+		return "", err
+	}
+	return LOG_LEVELS[atomic.SwapInt32(&logLevel, i)], nil
+}
+
+// currentLogLevel returns the name of the log level
+func currentLogLevel() string {
+	return LOG_LEVELS[atomic.LoadInt32(&logLevel)]
+}
+
+// logf logs a message of the given level when the log level lets it through
+func logf(level string, format string, args ...interface{}) {
+	i, err := logLevelIndex(level)
+	if err != nil || i < atomic.LoadInt32(&logLevel) {
+		return
+	}
+	log.Printf(strings.ToUpper(level)+" "+format, args...)
+}
+
+// logInterceptor logs every call with its duration and status code at the debug level
+func logInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logf("debug", "%s %s in %s", info.FullMethod, status.Code(err), time.Since(start))
+	return resp, err
+}
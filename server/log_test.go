@@ -0,0 +1,30 @@
+// This file contains tests for the leveled logging.
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestLogf tests that the messages below the log level are dropped
+func TestLogf(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	defer setLogLevel(DEFAULT_LOG_LEVEL)
+
+	if _, err := setLogLevel("warn"); err != nil {
+		t.Fatal(err)
+	}
+	logf("info", "dropped")
+	logf("error", "kept %d", 1)
+	if out := buf.String(); strings.Contains(out, "dropped") || !strings.Contains(out, "ERROR kept 1") {
+		t.Errorf("expected only the error logged, got %q", out)
+	}
+	if _, err := setLogLevel("verbose"); err == nil || currentLogLevel() != "warn" {
+		t.Errorf("expected an unknown level rejected, got %v and level %s", err, currentLogLevel())
+	}
+}
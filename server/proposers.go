@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	types "grpc_server4/proto/generated"
+	"sort"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"google.golang.org/grpc"
+)
+
+// GetProposers returns the proposer of every block between two heights, and for every validator of the
+// validator-sets the number of blocks it proposed versus the number expected from its voting power
+func (s *server) GetProposers(ctx context.Context, req *types.GetProposersRequest) (*types.GetProposersResponse, error) {
+	if err := checkBlockRange(req.FromHeight, req.ToHeight); err != nil {
+		return nil, err
+	}
+	grpcConn, _ := grpc.Dial(
+		chainFromContext(ctx).GRPCAddress, // your gRPC server address.
+		grpc.WithInsecure(),
+		upstreamCallOptions(),
+	)
+	defer func(grpcConn *grpc.ClientConn) {
+		err := grpcConn.Close()
+		if err != nil {
+			fmt.Println("grpcConn.Close() err:", err)
+		}
+	}(grpcConn)
+	ans, err := proposerReport(ctx, tmservice.NewServiceClient(grpcConn), req.FromHeight, req.ToHeight)
+	if err != nil {
+		return nil, err
+	}
+	ops := operatorsAt(ctx, stakingtypes.NewQueryClient(grpcConn), req.ToHeight)
+	for _, b := range ans.Blocks {
+		b.Operator = ops[b.Address]
+	}
+	for _, c := range ans.Validators {
+		c.Operator = ops[c.Address]
+	}
+	return ans, nil
+}
+
+// proposerReport reads the proposer of every block from from to to, and adds to the expected count of each
+// validator of the set at that height its share of the total voting power.
+// Tendermint's weighted round-robin makes the proposals of a validator proportional to its voting power.
+func proposerReport(ctx context.Context, client tmservice.ServiceClient, from, to int64) (*types.GetProposersResponse, error) {
+	ans := &types.GetProposersResponse{
+		FromHeight: from,
+		ToHeight:   to,
+		Blocks:     make([]*types.BlockProposer, 0, to-from+1),
+	}
+	counts := make(map[string]*types.ProposerCount)
+	sets := newValidatorSets(client)
+	for height := from; height <= to; height++ {
+		block, err := client.GetBlockByHeight(ctx, &tmservice.GetBlockByHeightRequest{Height: height})
+		if err != nil {
+			return nil, err
+		}
+		header := block.Block.Header
+		set, err := sets.at(ctx, header)
+		if err != nil {
+			return nil, err
+		}
+		total := totalVotingPower(set)
+		for _, v := range set {
+			c, ok := counts[v.ConsensusAddress]
+			if !ok {
+				c = &types.ProposerCount{Address: v.ConsensusAddress}
+				counts[v.ConsensusAddress] = c
+			}
+			if total > 0 {
+				c.Expected += float64(v.VotingPower) / float64(total)
+			}
+		}
+		address := chainFromContext(ctx).consAddress(header.ProposerAddress)
+		c, ok := counts[address]
+		if !ok {
+			return nil, fmt.Errorf("proposer %s of block %d is not in its validator-set", address, height)
+		}
+		c.Proposed++
+		ans.Blocks = append(ans.Blocks, &types.BlockProposer{
+			Height:     height,
+			Address:    address,
+			AddressHex: fmt.Sprintf("%X", header.ProposerAddress),
+		})
+	}
+	ans.Validators = make([]*types.ProposerCount, 0, len(counts))
+	for _, c := range counts {
+		if c.Expected > 0 {
+			c.Ratio = float64(c.Proposed) / c.Expected
+		}
+		ans.Validators = append(ans.Validators, c)
+	}
+	sort.Slice(ans.Validators, func(i, j int) bool {
+		if ans.Validators[i].Proposed != ans.Validators[j].Proposed {
+			return ans.Validators[i].Proposed > ans.Validators[j].Proposed
+		}
+		return ans.Validators[i].Address < ans.Validators[j].Address
+	})
+	return ans, nil
+}
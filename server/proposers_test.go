@@ -0,0 +1,66 @@
+// This file contains tests for the proposer report behind GetProposers.
+package main
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+)
+
+// TestProposerReport tests that proposals are counted per validator and compared with their voting power share
+func TestProposerReport(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	set := testValidatorSet(3)
+	client := &fakeValidatorService{
+		sets:   map[int64][]*tmservice.Validator{1: set},
+		blocks: map[int64]*tmproto.Block{},
+	}
+	// voting powers 1, 2 and 3 out of 6, so over 6 blocks the validators are expected to propose 1, 2 and 3
+	for i, proposer := range []int{2, 1, 2, 0, 2, 1} {
+		addr, _ := sdk.ConsAddressFromBech32(set[proposer].Address)
+		height := int64(i + 1)
+		client.blocks[height] = &tmproto.Block{Header: tmproto.Header{Height: height, ValidatorsHash: []byte("set"), ProposerAddress: addr}}
+	}
+
+	ans, err := proposerReport(context.Background(), client, 1, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ans.Blocks) != 6 || ans.Blocks[3].Address != set[0].Address || ans.Blocks[3].Height != 4 {
+		t.Errorf("unexpected blocks %v", ans.Blocks)
+	}
+	if len(ans.Validators) != 3 {
+		t.Fatalf("unexpected validators %v", ans.Validators)
+	}
+	for i, c := range ans.Validators {
+		want := set[2-i]
+		if c.Address != want.Address || c.Proposed != want.VotingPower || math.Abs(c.Expected-float64(want.VotingPower)) > 1e-9 || math.Abs(c.Ratio-1) > 1e-9 {
+			t.Errorf("unexpected proposer count %v", c)
+		}
+	}
+	if len(client.requested) != 1 {
+		t.Errorf("expected 1 validator-set fetch, got %d", len(client.requested))
+	}
+}
+
+// TestProposerReportUnknownProposer tests that a proposer outside the validator-set of its block is rejected
+func TestProposerReportUnknownProposer(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	client := &fakeValidatorService{
+		sets: map[int64][]*tmservice.Validator{1: testValidatorSet(2)},
+		blocks: map[int64]*tmproto.Block{
+			1: {Header: tmproto.Header{Height: 1, ValidatorsHash: []byte("set"), ProposerAddress: testPubKey(5).Address()}},
+		},
+	}
+	if _, err := proposerReport(context.Background(), client, 1, 1); err == nil {
+		t.Error("expected an error for a proposer outside the validator-set")
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	types "grpc_server4/proto/generated"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// quorumReader reads the same data from several upstreams of a chain and returns the result enough of them agree on
+type quorumReader struct {
+	addresses []string
+	required  int
+	// dial connects to an upstream, returning the function closing the connection
+	dial func(address string) (tmservice.ServiceClient, func(), error)
+}
+
+// quorumAnswer is what an upstream returned: the key identifying its result, and the result
+type quorumAnswer struct {
+	key   string
+	value interface{}
+	err   error
+}
+
+// quorumValidatorSet is the validator-set returned by an upstream to a quorum read
+type quorumValidatorSet struct {
+	height     int64
+	validators []*tmservice.Validator
+	pagination *query.PageResponse
+}
+
+// quorumReader returns the quorum reader of the chain, reading from GRPCAddress and the quorum upstreams
+func (c *Chain) quorumReader() (*quorumReader, error) {
+	if len(c.QuorumUpstreams) == 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "chain %s has no quorum upstreams", c.ChainID)
+	}
+	addresses := append([]string{c.GRPCAddress}, c.QuorumUpstreams...)
+	required := c.Quorum
+	if required == 0 {
+		required = len(addresses)/2 + 1
+	}
+	return &quorumReader{addresses: addresses, required: required, dial: dialUpstream}, nil
+}
+
+// dialUpstream connects to the tmservice of an upstream
+func dialUpstream(address string) (tmservice.ServiceClient, func(), error) {
+	grpcConn, err := grpc.Dial(
+		address, // your gRPC server address.
+		grpc.WithInsecure(),
+		upstreamCallOptions(),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tmservice.NewServiceClient(grpcConn), func() {
+		err := grpcConn.Close()
+		if err != nil {
+			fmt.Println("grpcConn.Close() err:", err)
+		}
+	}, nil
+}
+
+// read calls read on every upstream at once and returns the result with the most upstreams agreeing on its key.
+// It fails with Unavailable when too few upstreams answered to reach the quorum, and with DataLoss when enough
+// answered but not enough agreed. The report tells what each upstream answered, it is in the error details on failure.
+func (q *quorumReader) read(ctx context.Context, read func(ctx context.Context, client tmservice.ServiceClient) (string, interface{}, error)) (interface{}, *types.QuorumReport, error) {
+	answers := make([]quorumAnswer, len(q.addresses))
+	var wg sync.WaitGroup
+	for i, address := range q.addresses {
+		wg.Add(1)
+		go func(i int, address string) {
+			defer wg.Done()
+			client, closeConn, err := q.dial(address)
+			if err != nil {
+				answers[i].err = err
+				return
+			}
+			defer closeConn()
+			answers[i].key, answers[i].value, answers[i].err = read(ctx, client)
+		}(i, address)
+	}
+	wg.Wait()
+
+	// the most agreed key wins, the one of the first upstream on a tie
+	votes := make(map[string]int)
+	best, answered := "", 0
+	for _, a := range answers {
+		if a.err != nil {
+			continue
+		}
+		answered++
+		votes[a.key]++
+		if votes[a.key] > votes[best] {
+			best = a.key
+		}
+	}
+	report := &types.QuorumReport{
+		Required:  uint32(q.required),
+		Agreed:    uint32(votes[best]),
+		Upstreams: make([]*types.UpstreamAnswer, 0, len(answers)),
+	}
+	var value interface{}
+	for i, a := range answers {
+		answer := &types.UpstreamAnswer{Address: q.addresses[i], Result: a.key}
+		if a.err != nil {
+			answer.Error = a.err.Error()
+		} else if answer.Agrees = a.key == best; answer.Agrees && value == nil {
+			value = a.value
+		}
+		report.Diverged = report.Diverged || !answer.Agrees
+		report.Upstreams = append(report.Upstreams, answer)
+	}
+	var st *status.Status
+	switch {
+	case votes[best] >= q.required:
+		return value, report, nil
+	case answered < q.required:
+		st = status.Newf(codes.Unavailable, "%d of %d upstreams answered, %d must agree: %s",
+			answered, len(answers), q.required, describeAnswers(report))
+	default:
+		st = status.Newf(codes.DataLoss, "upstreams diverge, %d must agree: %s", q.required, describeAnswers(report))
+	}
+	// the report travels in the error details, so clients can tell which upstream disagreed
+	if detailed, err := st.WithDetails(report); err == nil {
+		st = detailed
+	}
+	return nil, report, st.Err()
+}
+
+// describeAnswers lists the answer of every upstream of a report, grouping the ones agreeing on a result
+func describeAnswers(report *types.QuorumReport) string {
+	byResult := make(map[string][]string)
+	var failed []string
+	for _, a := range report.Upstreams {
+		if a.Error != "" {
+			failed = append(failed, fmt.Sprintf("%s failed: %s", a.Address, a.Error))
+		} else {
+			byResult[a.Result] = append(byResult[a.Result], a.Address)
+		}
+	}
+	parts := make([]string, 0, len(byResult)+len(failed))
+	for result, addresses := range byResult {
+		parts = append(parts, fmt.Sprintf("%s returned %s", strings.Join(addresses, ", "), result))
+	}
+	sort.Strings(parts)
+	return strings.Join(append(parts, failed...), "; ")
+}
+
+// quorumBlock reads the block at height from the quorum upstreams, which must agree on its block id
+func (q *quorumReader) quorumBlock(ctx context.Context, height int64) (*tmservice.GetBlockByHeightResponse, *types.QuorumReport, error) {
+	value, report, err := q.read(ctx, func(ctx context.Context, client tmservice.ServiceClient) (string, interface{}, error) {
+		block, err := client.GetBlockByHeight(ctx, &tmservice.GetBlockByHeightRequest{Height: height})
+		if err != nil {
+			return "", nil, err
+		}
+		if block.BlockId == nil {
+			return "", nil, fmt.Errorf("block %d has no block id", height)
+		}
+		return fmt.Sprintf("%X", block.BlockId.Hash), block, nil
+	})
+	if err != nil {
+		return nil, report, err
+	}
+	return value.(*tmservice.GetBlockByHeightResponse), report, nil
+}
+
+// quorumValidators reads the validator-set at height from the quorum upstreams, which must agree on its hash.
+// With all the complete set is read, otherwise the page of pagination.
+func (q *quorumReader) quorumValidators(ctx context.Context, chain *Chain, height int64, all bool, pagination *query.PageRequest) (*quorumValidatorSet, *types.QuorumReport, error) {
+	value, report, err := q.read(ctx, func(ctx context.Context, client tmservice.ServiceClient) (string, interface{}, error) {
+		set := &quorumValidatorSet{}
+		if all {
+			blockHeight, vs, err := completeValidatorSet(ctx, client, height)
+			if err != nil {
+				return "", nil, err
+			}
+			set.height, set.validators = blockHeight, vs
+			set.pagination = &query.PageResponse{Total: uint64(len(vs))}
+		} else {
+			valSet, err := client.GetValidatorSetByHeight(ctx, &tmservice.GetValidatorSetByHeightRequest{Pagination: pagination, Height: height})
+			if err != nil {
+				return "", nil, err
+			}
+			set.height, set.validators, set.pagination = valSet.BlockHeight, valSet.Validators, valSet.Pagination
+		}
+		tmSet, err := chain.tmValidatorSet(set.validators)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%X", tmSet.Hash()), set, nil
+	})
+	if err != nil {
+		return nil, report, err
+	}
+	return value.(*quorumValidatorSet), report, nil
+}
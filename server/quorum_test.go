@@ -0,0 +1,119 @@
+// This file contains tests for the quorum reads across several upstreams.
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	types "grpc_server4/proto/generated"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testQuorumReader returns a quorum reader of the upstreams by address, an unknown address failing to dial
+func testQuorumReader(required int, addresses []string, upstreams map[string]tmservice.ServiceClient) *quorumReader {
+	return &quorumReader{
+		addresses: addresses,
+		required:  required,
+		dial: func(address string) (tmservice.ServiceClient, func(), error) {
+			client, ok := upstreams[address]
+			if !ok {
+				return nil, nil, fmt.Errorf("connection refused")
+			}
+			return client, func() {}, nil
+		},
+	}
+}
+
+// TestQuorumBlock tests that the block most upstreams agree on is returned and the divergent upstream reported
+func TestQuorumBlock(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	chain := newTestChain(t, 4, 3)
+	fork := newRotatingTestChain(t, 4, 3, 1)
+	upstreams := map[string]tmservice.ServiceClient{"a": chain.service(), "b": fork.service(), "c": chain.service()}
+
+	q := testQuorumReader(2, []string{"a", "b", "c"}, upstreams)
+	block, report, err := q.quorumBlock(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprintf("%X", block.BlockId.Hash) != fmt.Sprintf("%X", chain.ids[2].Hash) {
+		t.Errorf("expected the block of the majority")
+	}
+	if report.Agreed != 2 || !report.Diverged || report.Upstreams[1].Agrees || !report.Upstreams[2].Agrees {
+		t.Errorf("expected b to be reported as divergent, got %v", report)
+	}
+
+	q = testQuorumReader(3, []string{"a", "b", "c"}, upstreams)
+	_, _, err = q.quorumBlock(context.Background(), 2)
+	st := status.Convert(err)
+	if st.Code() != codes.DataLoss || !strings.Contains(st.Message(), "b returned") {
+		t.Fatalf("expected the divergence of b to fail the read, got %v", err)
+	}
+	if len(st.Details()) != 1 {
+		t.Fatalf("expected the report in the error details, got %v", st.Details())
+	}
+	if report, ok := st.Details()[0].(*types.QuorumReport); !ok || report.Agreed != 2 {
+		t.Errorf("unexpected error details %v", st.Details()[0])
+	}
+
+	q = testQuorumReader(2, []string{"a", "d", "e"}, upstreams)
+	if _, _, err := q.quorumBlock(context.Background(), 2); status.Code(err) != codes.Unavailable {
+		t.Errorf("expected Unavailable when too few upstreams answer, got %v", err)
+	}
+}
+
+// TestQuorumValidators tests that the upstreams must agree on the validators hash of the set
+func TestQuorumValidators(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	chain := newTestChain(t, 4, 3)
+	fork := newRotatingTestChain(t, 4, 3, 1)
+	upstreams := map[string]tmservice.ServiceClient{"a": chain.service(), "b": fork.service(), "c": chain.service()}
+
+	q := testQuorumReader(2, []string{"a", "b", "c"}, upstreams)
+	set, report, err := q.quorumValidators(context.Background(), DefaultChain, 3, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.validators) != 4 || report.Upstreams[0].Result != fmt.Sprintf("%X", chain.sets[3].Hash()) {
+		t.Errorf("expected the set of the majority, got %d validators and %v", len(set.validators), report)
+	}
+	if !report.Diverged || report.Upstreams[1].Agrees {
+		t.Errorf("expected b to be reported as divergent, got %v", report)
+	}
+}
+
+// TestChainQuorumReader tests the upstreams and quorum of a chain
+func TestChainQuorumReader(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	if _, err := DefaultChain.quorumReader(); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition without quorum upstreams, got %v", err)
+	}
+	conf := testChainConfigs[0]
+	conf.QuorumUpstreams = []string{"b:9090", "c:9090", "d:9090"}
+	chain, err := newChain(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := chain.quorumReader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.addresses) != 4 || q.addresses[0] != conf.GRPCAddress || q.required != 3 {
+		t.Errorf("expected a majority of 3 of 4 upstreams, got %d of %v", q.required, q.addresses)
+	}
+	conf.Quorum = 5
+	if _, err := newChain(conf); err == nil {
+		t.Error("expected a quorum larger than the upstreams to be rejected")
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// CONFIG_RELOAD_DELAY is how long the config file must stay unchanged before it is reloaded, so an editor
+// writing it in several steps triggers a single reload
+const CONFIG_RELOAD_DELAY = 500 * time.Millisecond
+
+// reloadMtx serializes the applications of a config
+var reloadMtx sync.Mutex
+
+// applyConfig makes config the running configuration: it replaces the chain registry, keeping the chains whose
+// config is unchanged, and the log level. The requests in flight finish with the chain they started with.
+// The grpc_web section and the upstream options of grpc are read from the running config on every request, so they
+// are reloaded too; the listeners, the admin listener and the server message sizes are only applied on restart.
+// An invalid config is rejected as a whole and the running one is kept.
+func applyConfig(ctx context.Context, config *Config) error {
+	reloadMtx.Lock()
+	defer reloadMtx.Unlock()
+	logLevel := config.LogLevel
+	if logLevel == "" {
+		logLevel = DEFAULT_LOG_LEVEL
+	}
+	if _, err := logLevelIndex(logLevel); err != nil {
+		return err
+	}
+	if err := config.GRPC.validate(); err != nil {
+		return err
+	}
+	if err := validateListeners(config); err != nil {
+		return err
+	}
+	registryMtx.RLock()
+	previous := Chains
+	registryMtx.RUnlock()
+	chains, defaultChain, err := buildChains(config, previous)
+	if err != nil {
+		return err
+	}
+
+	registryMtx.Lock()
+	Chains, DefaultChain, LoadedConfig = chains, defaultChain, config
+	registryMtx.Unlock()
+	setLogLevel(logLevel)
+
+	running := make(map[*eventSource]bool, len(chains))
+	for _, chain := range chains {
+		running[chain.Events] = true
+		chain.Events.start(ctx)
+	}
+	for _, chain := range previous {
+		if !running[chain.Events] {
+			chain.Events.stop()
+		}
+	}
+	return nil
+}
+
+// runningConfig returns the running configuration
+func runningConfig() *Config {
+	registryMtx.RLock()
+	defer registryMtx.RUnlock()
+	return LoadedConfig
+}
+
+// reloadConfig reads the config file at path again and applies it, keeping the running config when it is invalid
+func reloadConfig(ctx context.Context, path string) error {
+	config, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+	running := runningConfig()
+	if config.Admin != running.Admin {
+		logf("warn", "the admin listener changed to %q, it is applied on restart", config.Admin.Listen)
+	}
+	if !reflect.DeepEqual(listenerConfigs(config), listenerConfigs(running)) {
+		logf("warn", "the listeners changed, they are applied on restart")
+	}
+	if config.GRPC.MaxRecvMsgSize != running.GRPC.MaxRecvMsgSize || config.GRPC.MaxSendMsgSize != running.GRPC.MaxSendMsgSize {
+		logf("warn", "the server message sizes changed, they are applied on restart")
+	}
+	if err := applyConfig(ctx, config); err != nil {
+		return err
+	}
+	logf("info", "reloaded %s", path)
+	return nil
+}
+
+// watchConfig reloads the config file at path on SIGHUP and whenever the file changes, until ctx is done
+func watchConfig(ctx context.Context, path string) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	// the directory is watched since editors replace the file instead of writing it, and a config map swaps the ..data
+	// symlink the file resolves through, so any event of the directory reloads once the file resolves elsewhere
+	target, _ := filepath.EvalSymlinks(path)
+	var events <-chan fsnotify.Event
+	var errors <-chan error
+	watcher, err := fsnotify.NewWatcher()
+	if err == nil {
+		defer watcher.Close()
+		err = watcher.Add(filepath.Dir(path))
+	}
+	if err != nil {
+		logf("warn", "%s is only reloaded on SIGHUP: %v", path, err)
+	} else {
+		events, errors = watcher.Events, watcher.Errors
+	}
+
+	reload := func() {
+		if err := reloadConfig(ctx, path); err != nil {
+			logf("error", "rejected the config %s, keeping the running one: %v", path, err)
+		}
+	}
+	changed := time.NewTimer(CONFIG_RELOAD_DELAY)
+	changed.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			reload()
+		case event := <-events:
+			resolved, _ := filepath.EvalSymlinks(path)
+			name := filepath.Clean(event.Name)
+			written := (name == filepath.Clean(path) || name == resolved) && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0
+			if written || resolved != target {
+				target = resolved
+				changed.Reset(CONFIG_RELOAD_DELAY)
+			}
+		case <-changed.C:
+			reload()
+		case err := <-errors:
+			logf("warn", "watching %s: %v", path, err)
+		}
+	}
+}
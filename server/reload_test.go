@@ -0,0 +1,203 @@
+// This file contains tests for the config reload.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// TestApplyConfig tests that a reload keeps the unchanged chains and that an invalid config keeps the running one
+func TestApplyConfig(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+	defer InitCcontext()
+	defer func() { LoadedConfig = &Config{} }()
+	defer setLogLevel(DEFAULT_LOG_LEVEL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	builtin := DefaultChain
+	if err := applyConfig(ctx, &Config{}); err != nil {
+		t.Fatal(err)
+	}
+	if DefaultChain != builtin {
+		t.Error("expected the built-in chain kept")
+	}
+
+	if err := applyConfig(ctx, &Config{Chains: testChainConfigs, LogLevel: "warn"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(Chains) != 2 || DefaultChain.ChainID != "osmosis-1" || currentLogLevel() != "warn" {
+		t.Fatalf("expected the registry of 2 chains at the warn level, got %d chains at %s", len(Chains), currentLogLevel())
+	}
+	osmosis, cosmoshub := Chains["osmosis-1"], Chains["cosmoshub-4"]
+
+	changed := []ChainConfig{testChainConfigs[0], testChainConfigs[1]}
+	changed[1].GRPCAddress = "cosmoshub-2:9090"
+	if err := applyConfig(ctx, &Config{Chains: changed}); err != nil {
+		t.Fatal(err)
+	}
+	if Chains["osmosis-1"] != osmosis {
+		t.Error("expected the unchanged chain kept")
+	}
+	if Chains["cosmoshub-4"] == cosmoshub || Chains["cosmoshub-4"].GRPCAddress != "cosmoshub-2:9090" {
+		t.Error("expected the changed chain replaced")
+	}
+	if currentLogLevel() != DEFAULT_LOG_LEVEL {
+		t.Errorf("expected the default log level when the config does not set one, got %s", currentLogLevel())
+	}
+
+	running := LoadedConfig
+	invalid := map[string]*Config{
+		"log level": {Chains: testChainConfigs, LogLevel: "trace"},
+		"duplicate": {Chains: []ChainConfig{testChainConfigs[0], testChainConfigs[0]}},
+		"default":   {Chains: testChainConfigs, DefaultChain: "juno-1"},
+	}
+	for name, config := range invalid {
+		if err := applyConfig(ctx, config); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		if LoadedConfig != running || Chains["cosmoshub-4"].GRPCAddress != "cosmoshub-2:9090" {
+			t.Fatalf("%s: expected the running config kept", name)
+		}
+	}
+}
+
+// TestWatchConfig tests that a change of the config file is applied and that an invalid file is rejected
+func TestWatchConfig(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+	defer InitCcontext()
+	defer func() { LoadedConfig = &Config{} }()
+	defer setLogLevel(DEFAULT_LOG_LEVEL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(config string) {
+		if err := ioutil.WriteFile(path, []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("log_level: info\n")
+	done := make(chan struct{})
+	go func() {
+		watchConfig(ctx, path)
+		close(done)
+	}()
+	// the watcher is stopped before the registry is reset
+	defer func() {
+		cancel()
+		<-done
+	}()
+	// waits for the watcher to apply the config
+	applied := func(ok func() bool) bool {
+		for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+			registryMtx.RLock()
+			done := ok()
+			registryMtx.RUnlock()
+			if done {
+				return true
+			}
+		}
+		return false
+	}
+
+	bz, err := yaml.Marshal(&Config{Chains: testChainConfigs, LogLevel: "debug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the watcher may not have started yet, the file is written until it is applied
+	for i := 0; i < 10; i++ {
+		write(string(bz))
+		if applied(func() bool { return len(Chains) == 2 }) {
+			break
+		}
+	}
+	if len(Chains) != 2 || currentLogLevel() != "debug" {
+		t.Fatalf("expected the written config applied, got %d chains at %s", len(Chains), currentLogLevel())
+	}
+
+	running := LoadedConfig
+	write("chains: [")
+	time.Sleep(2 * CONFIG_RELOAD_DELAY)
+	write("log_level: trace\n")
+	time.Sleep(2 * CONFIG_RELOAD_DELAY)
+	if LoadedConfig != running || len(Chains) != 2 {
+		t.Error("expected the invalid configs rejected")
+	}
+}
+
+// TestWatchConfigMap tests that the config is reloaded when a config map swaps the ..data symlink it resolves through
+func TestWatchConfigMap(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+	defer InitCcontext()
+	defer func() { LoadedConfig = &Config{} }()
+	defer setLogLevel(DEFAULT_LOG_LEVEL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// laid out like a mounted config map: config.yaml -> ..data/config.yaml, ..data -> ..<version>
+	dir := t.TempDir()
+	version := 0
+	swap := func(config string) {
+		version++
+		data := fmt.Sprintf("..v%d", version)
+		if err := os.Mkdir(filepath.Join(dir, data), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, data, "config.yaml"), []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(data, filepath.Join(dir, "..data_tmp")); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	swap("log_level: info\n")
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.Symlink(filepath.Join("..data", "config.yaml"), path); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		watchConfig(ctx, path)
+		close(done)
+	}()
+	// the watcher is stopped before the registry is reset
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	bz, err := yaml.Marshal(&Config{Chains: testChainConfigs, LogLevel: "debug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the watcher may not have started yet, the config map is updated until it is applied
+	for i := 0; i < 10; i++ {
+		swap(string(bz))
+		applied := false
+		for deadline := time.Now().Add(time.Second); !applied && time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+			registryMtx.RLock()
+			applied = len(Chains) == 2
+			registryMtx.RUnlock()
+		}
+		if applied {
+			break
+		}
+	}
+	if len(Chains) != 2 || currentLogLevel() != "debug" {
+		t.Fatalf("expected the config map update applied, got %d chains at %s", len(Chains), currentLogLevel())
+	}
+}
@@ -5,20 +5,23 @@ import (
 	"encoding/json"
 	"fmt"
 	types "grpc_server4/proto/generated"
-	"io/ioutil"
+	"grpc_server4/tmrpc"
 	"log"
-	"net"
 	"net/http"
 	"os"
+	"strconv"
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
 	"github.com/osmosis-labs/osmosis/v12/app"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
-	anypb "google.golang.org/protobuf/types/known/anypb"
 )
 
 // The address of the gRPC server to connect to
@@ -30,10 +33,14 @@ const CHAIN_ID = "osmosis-1"
 // The URL of the Osmosis node to connect to
 const NODE_URL = "https://osmosis-mainnet-rpc.allthatnode.com:26657"
 
-// Ccontext is a global client context object initialized with the chain ID and node URI
+// The URL of the Tendermint RPC of the built-in Osmosis chain
+const RPC_URL = "https://rpc.osmosis.zone"
+
+// Ccontext is a global client context object initialized with the chain ID and node URI of the built-in Osmosis chain
 var Ccontext = client.Context{}.WithChainID(CHAIN_ID)
 
-// InitCcontext initializes the Ccontext object with the necessary parameters for communicating with the Osmosis node
+// InitCcontext initializes the Ccontext object with the necessary parameters for communicating with the Osmosis node,
+// and registers the built-in Osmosis chain as the default chain
 func InitCcontext() {
 	encodingConfig := app.MakeEncodingConfig()
 	Ccontext = Ccontext.WithCodec(encodingConfig.Marshaler).
@@ -47,6 +54,26 @@ func InitCcontext() {
 		WithNodeURI(NODE_URL)
 	conf := sdk.GetConfig()
 	conf.SetBech32PrefixForAccount("osmo", "osmopub")
+	conf.SetBech32PrefixForValidator("osmovaloper", "osmovaloperpub")
+	conf.SetBech32PrefixForConsensusNode("osmovalcons", "osmovalconspub")
+	DefaultChain = newBuiltinChain()
+	Chains = map[string]*Chain{CHAIN_ID: DefaultChain}
+}
+
+// newBuiltinChain returns the built-in Osmosis chain, without light client
+func newBuiltinChain() *Chain {
+	return &Chain{
+		ChainConfig: ChainConfig{
+			ChainID:      CHAIN_ID,
+			GRPCAddress:  GRPC_SERVER_ADDRESS,
+			RPCAddress:   RPC_URL,
+			Bech32Prefix: "osmo",
+			Encoding:     DEFAULT_ENCODING,
+		},
+		Context: Ccontext,
+		RPC:     tmrpc.New(RPC_URL),
+		Events:  newEventSource(CHAIN_ID, RPC_URL),
+	}
 }
 
 // server is a struct representing the gRPC server and its methods
@@ -58,8 +85,9 @@ type server struct {
 // GetNodeInfo returns information about the connected node
 func (s *server) GetNodeInfo(ctx context.Context, req *types.GetNodeInfoRequest) (*types.GetNodeInfoResponse, error) {
 	grpcConn, _ := grpc.Dial(
-		GRPC_SERVER_ADDRESS, // your gRPC server address.
-		grpc.WithInsecure(), // The SDK doesn't support any transport security mechanism.
+		chainFromContext(ctx).GRPCAddress, // your gRPC server address.
+		grpc.WithInsecure(),               // The SDK doesn't support any transport security mechanism.
+		upstreamCallOptions(),
 	)
 	defer func(grpcConn *grpc.ClientConn) {
 		err := grpcConn.Close()
@@ -98,8 +126,9 @@ func (s *server) GetNodeInfo(ctx context.Context, req *types.GetNodeInfoRequest)
 
 func (s *server) GetSyncing(ctx context.Context, req *types.GetSyncingRequest) (*types.GetSyncingResponse, error) {
 	grpcConn, _ := grpc.Dial(
-		GRPC_SERVER_ADDRESS, // your gRPC server address.
+		chainFromContext(ctx).GRPCAddress, // your gRPC server address.
 		grpc.WithInsecure(),
+		upstreamCallOptions(),
 	)
 	defer func(grpcConn *grpc.ClientConn) {
 		err := grpcConn.Close()
@@ -118,8 +147,9 @@ func (s *server) GetSyncing(ctx context.Context, req *types.GetSyncingRequest) (
 
 func (s *server) GetLatestBlock(ctx context.Context, req *types.GetLatestBlockRequest) (*types.GetLatestBlockResponse, error) {
 	grpcConn, _ := grpc.Dial(
-		GRPC_SERVER_ADDRESS, // your gRPC server address.
+		chainFromContext(ctx).GRPCAddress, // your gRPC server address.
 		grpc.WithInsecure(),
+		upstreamCallOptions(),
 	)
 	defer func(grpcConn *grpc.ClientConn) {
 		err := grpcConn.Close()
@@ -128,21 +158,32 @@ func (s *server) GetLatestBlock(ctx context.Context, req *types.GetLatestBlockRe
 		}
 	}(grpcConn)
 	client := tmservice.NewServiceClient(grpcConn)
-	latestBlock, err := client.GetLatestBlock(ctx, &tmservice.GetLatestBlockRequest{})
-	if err != nil {
-		return nil, err
+	// the block pushed by the event source saves the upstream call, which is only made when it is not connected
+	blockID, block := chainFromContext(ctx).Events.latest()
+	if block == nil {
+		latestBlock, err := client.GetLatestBlock(ctx, &tmservice.GetLatestBlockRequest{})
+		if err != nil {
+			return nil, err
+		}
+		blockID, block = latestBlock.BlockId, latestBlock.Block
 	}
 
-	return &types.GetLatestBlockResponse{
-		BlockId: latestBlock.BlockId,
-		Block:   latestBlock.Block,
-	}, nil
+	ans := &types.GetLatestBlockResponse{
+		BlockId: blockID,
+		Block:   block,
+	}
+	if req.DecodeTxs {
+		ans.DecodedTxs = chainFromContext(ctx).decodeTxs(block.Data.Txs)
+	}
+	ans.Verified, ans.LightClientError = lightVerify(ctx, client, block.Header)
+	return ans, nil
 }
 
 func (s *server) GetBlockByHeight(ctx context.Context, req *types.GetBlockByHeightRequest) (*types.GetBlockByHeightResponse, error) {
 	grpcConn, _ := grpc.Dial(
-		GRPC_SERVER_ADDRESS, // your gRPC server address.
+		chainFromContext(ctx).GRPCAddress, // your gRPC server address.
 		grpc.WithInsecure(),
+		upstreamCallOptions(),
 	)
 	defer func(grpcConn *grpc.ClientConn) {
 		err := grpcConn.Close()
@@ -151,22 +192,49 @@ func (s *server) GetBlockByHeight(ctx context.Context, req *types.GetBlockByHeig
 		}
 	}(grpcConn)
 	client := tmservice.NewServiceClient(grpcConn)
-	block, err := client.GetBlockByHeight(ctx, &tmservice.GetBlockByHeightRequest{
-		Height: req.Height,
-	})
-	if err != nil {
-		return nil, err
+	var block *tmservice.GetBlockByHeightResponse
+	var report *types.QuorumReport
+	if req.Quorum {
+		q, err := chainFromContext(ctx).quorumReader()
+		if err != nil {
+			return nil, err
+		}
+		if block, report, err = q.quorumBlock(ctx, req.Height); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		block, err = client.GetBlockByHeight(ctx, &tmservice.GetBlockByHeightRequest{
+			Height: req.Height,
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
-	return &types.GetBlockByHeightResponse{
+	ans := &types.GetBlockByHeightResponse{
 		BlockId: block.BlockId,
 		Block:   block.Block,
-	}, nil
+		Quorum:  report,
+	}
+	if req.DecodeTxs {
+		ans.DecodedTxs = chainFromContext(ctx).decodeTxs(block.Block.Data.Txs)
+	}
+	if req.Verify {
+		var err error
+		ans.Verification, err = verifyBlock(ctx, client, block.BlockId, block.Block)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ans.Verified, ans.LightClientError = lightVerify(ctx, client, block.Block.Header)
+	return ans, nil
 }
 
 func (s *server) GetLatestValidatorSet(ctx context.Context, req *types.GetLatestValidatorSetRequest) (*types.GetLatestValidatorSetResponse, error) {
 	grpcConn, _ := grpc.Dial(
-		GRPC_SERVER_ADDRESS, // your gRPC server address.
+		chainFromContext(ctx).GRPCAddress, // your gRPC server address.
 		grpc.WithInsecure(),
+		upstreamCallOptions(),
 	)
 	defer func(grpcConn *grpc.ClientConn) {
 		err := grpcConn.Close()
@@ -175,35 +243,35 @@ func (s *server) GetLatestValidatorSet(ctx context.Context, req *types.GetLatest
 		}
 	}(grpcConn)
 	client := tmservice.NewServiceClient(grpcConn)
-	valSet, err := client.GetLatestValidatorSet(ctx, &tmservice.GetLatestValidatorSetRequest{Pagination: req.Pagination})
-	if err != nil {
-		return nil, err
-	}
-	validators := make([]*types.Validator, 0, 0)
-	for _, v := range valSet.Validators {
-		validator := &types.Validator{
-			Address: v.Address,
-			PubKey: &anypb.Any{
-				TypeUrl: v.PubKey.TypeUrl,
-				Value:   v.PubKey.Value,
-			},
-			VotingPower:      v.VotingPower,
-			ProposerPriority: v.ProposerPriority,
+	ans := &types.GetLatestValidatorSetResponse{}
+	if req.All {
+		height, all, err := completeValidatorSet(ctx, client, 0)
+		if err != nil {
+			return nil, err
 		}
-		validators = append(validators, validator)
+		ans.BlockHeight = height
+		ans.Validators = chainFromContext(ctx).validators(all)
+		ans.Pagination = &query.PageResponse{Total: uint64(len(all))}
+		ans.TotalVotingPower = totalVotingPower(ans.Validators)
+	} else {
+		valSet, err := client.GetLatestValidatorSet(ctx, &tmservice.GetLatestValidatorSetRequest{Pagination: req.Pagination})
+		if err != nil {
+			return nil, err
+		}
+		ans.BlockHeight = valSet.BlockHeight
+		ans.Validators = chainFromContext(ctx).validators(valSet.Validators)
+		ans.Pagination = valSet.Pagination
 	}
-	return &types.GetLatestValidatorSetResponse{
-		BlockHeight: valSet.BlockHeight,
-		Validators:  validators,
-		Pagination:  valSet.Pagination,
-	}, nil
+	joinOperatorsAt(ctx, stakingtypes.NewQueryClient(grpcConn), ans.Validators, ans.BlockHeight)
+	return ans, nil
 
 }
 
 func (s *server) GetValidatorSetByHeight(ctx context.Context, req *types.GetValidatorSetByHeightRequest) (*types.GetValidatorSetByHeightResponse, error) {
 	grpcConn, _ := grpc.Dial(
-		GRPC_SERVER_ADDRESS, // your gRPC server address.
+		chainFromContext(ctx).GRPCAddress, // your gRPC server address.
 		grpc.WithInsecure(),
+		upstreamCallOptions(),
 	)
 	defer func(grpcConn *grpc.ClientConn) {
 		err := grpcConn.Close()
@@ -212,42 +280,61 @@ func (s *server) GetValidatorSetByHeight(ctx context.Context, req *types.GetVali
 		}
 	}(grpcConn)
 	client := tmservice.NewServiceClient(grpcConn)
-	valSet, err := client.GetValidatorSetByHeight(ctx, &tmservice.GetValidatorSetByHeightRequest{Pagination: req.Pagination, Height: req.Height})
-	if err != nil {
-		return nil, err
-	}
-	validators := make([]*types.Validator, 0, 0)
-	for _, v := range valSet.Validators {
-		validator := &types.Validator{
-			Address: v.Address,
-			PubKey: &anypb.Any{
-				TypeUrl: v.PubKey.TypeUrl,
-				Value:   v.PubKey.Value,
-			},
-			VotingPower:      v.VotingPower,
-			ProposerPriority: v.ProposerPriority,
+	ans := &types.GetValidatorSetByHeightResponse{}
+	if req.Quorum {
+		chain := chainFromContext(ctx)
+		q, err := chain.quorumReader()
+		if err != nil {
+			return nil, err
+		}
+		set, report, err := q.quorumValidators(ctx, chain, req.Height, req.All, req.Pagination)
+		if err != nil {
+			return nil, err
 		}
-		validators = append(validators, validator)
+		ans.BlockHeight = set.height
+		ans.Validators = chain.validators(set.validators)
+		ans.Pagination = set.pagination
+		ans.Quorum = report
+		if req.All {
+			ans.TotalVotingPower = totalVotingPower(ans.Validators)
+		}
+	} else if req.All {
+		height, all, err := completeValidatorSet(ctx, client, req.Height)
+		if err != nil {
+			return nil, err
+		}
+		ans.BlockHeight = height
+		ans.Validators = chainFromContext(ctx).validators(all)
+		ans.Pagination = &query.PageResponse{Total: uint64(len(all))}
+		ans.TotalVotingPower = totalVotingPower(ans.Validators)
+	} else {
+		valSet, err := client.GetValidatorSetByHeight(ctx, &tmservice.GetValidatorSetByHeightRequest{Pagination: req.Pagination, Height: req.Height})
+		if err != nil {
+			return nil, err
+		}
+		ans.BlockHeight = valSet.BlockHeight
+		ans.Validators = chainFromContext(ctx).validators(valSet.Validators)
+		ans.Pagination = valSet.Pagination
 	}
-	return &types.GetValidatorSetByHeightResponse{
-		BlockHeight: valSet.BlockHeight,
-		Validators:  validators,
-		Pagination:  valSet.Pagination,
-	}, nil
+	joinOperatorsAt(ctx, stakingtypes.NewQueryClient(grpcConn), ans.Validators, ans.BlockHeight)
+	return ans, nil
 }
+
+// GetABCIInfo returns the abci_info of the node at the chain RPC address
 func (s *server) GetABCIInfo(ctx context.Context, req *types.GetABCIInfoRequest) (*types.GetABCIInfoResponse, error) {
-	resp, err := http.Get("https://rpc.osmosis.zone/abci_info")
+	node := chainFromContext(ctx).RPC
+	resp, err := node.Do(ctx, "abci_info", nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	var jsonResp map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&jsonResp)
-	if err != nil {
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	var info coretypes.ResultABCIInfo
+	if err := tmjson.Unmarshal(resp.Result, &info); err != nil {
 		return nil, err
 	}
-	jsonBytes, err := json.Marshal(jsonResp)
+	jsonBytes, err := json.Marshal(resp)
 	if err != nil {
 		return nil, err
 	}
@@ -255,64 +342,55 @@ func (s *server) GetABCIInfo(ctx context.Context, req *types.GetABCIInfoRequest)
 
 	return &types.GetABCIInfoResponse{
 		Jsonrpc: jsonStr,
-		Id:      int32(jsonResp["id"].(float64)),
+		Id:      int32(resp.ID),
 		Response: &types.ABCIResponse{
 			Data:       jsonStr,
-			Version:    jsonResp["result"].(map[string]interface{})["response"].(map[string]interface{})["version"].(string),
-			AppVersion: jsonResp["result"].(map[string]interface{})["response"].(map[string]interface{})["app_version"].(string),
+			Version:    info.Response.Version,
+			AppVersion: strconv.FormatUint(info.Response.AppVersion, 10),
 		},
 	}, nil
 }
 
+// GetStatusInfo returns the status of the node at the chain RPC address as JSON
 func (s *server) GetStatusInfo(ctx context.Context, req *types.GetStatusInfoRequest) (*types.GetStatusInfoResponse, error) {
-	client := &http.Client{}
-	reqDirect, err := http.NewRequest("GET", "https://rpc.osmosis.zone/status", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := client.Do(reqDirect)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
+	var result coretypes.ResultStatus
+	if err := chainFromContext(ctx).RPC.Call(ctx, "status", nil, &result); err != nil {
 		return nil, err
 	}
-
-	var result map[string]interface{}
-	err = json.Unmarshal(body, &result)
-	if err != nil {
-		return nil, err
-	}
-
-	jsonResp, err := json.Marshal(result["result"])
+	jsonResp, err := tmjson.Marshal(&result)
 	if err != nil {
 		return nil, err
 	}
 
-	responseStr := string(jsonResp)
-	fmt.Println(responseStr)
-
 	return &types.GetStatusInfoResponse{
-		ResponseString: responseStr,
+		ResponseString: string(jsonResp),
 	}, nil
 }
 
+// newGRPCServer returns the gRPC server of GrpcQueryService with the options of config
+func newGRPCServer(config *Config) *grpc.Server {
+	opts := append(config.GRPC.serverOptions(), grpc.ChainUnaryInterceptor(logInterceptor, chainInterceptor, heightInterceptor))
+	grpcServer := grpc.NewServer(opts...)
+	types.RegisterGrpcQueryServiceServer(grpcServer, &server{})
+	reflection.Register(grpcServer)
+	return grpcServer
+}
+
 func Serve() {
 	// Start grpc server
-	grpcListener, err := net.Listen("tcp", "localhost:9090")
-	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+	config := runningConfig()
+	grpcServer := newGRPCServer(config)
+	httpServer := &http.Server{Handler: httpHandler(grpcServer)}
+	errs := make(chan error, len(listenerConfigs(config)))
+	for _, listenerConfig := range listenerConfigs(config) {
+		listener, err := listenerConfig.listen()
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+		go func() { errs <- serveSplit(grpcServer, httpServer, listener) }()
+		fmt.Println("grpc server is started on", listenerConfig.Address)
 	}
-	grpcServer := grpc.NewServer()
-	types.RegisterGrpcQueryServiceServer(grpcServer, &server{})
-	reflection.Register(grpcServer)
-	fmt.Println("grpc server is started")
-	err = grpcServer.Serve(grpcListener)
-	if err != nil {
+	if err := <-errs; err != nil {
 		fmt.Printf("failed to serve: %v", err)
 		return
 	}
@@ -320,5 +398,20 @@ func Serve() {
 
 func main() {
 	InitCcontext()
+	config, err := loadConfig(CONFIG_FILE)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+	if err := applyConfig(context.Background(), config); err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+	go watchConfig(context.Background(), CONFIG_FILE)
+	if config.Admin.Listen != "" {
+		go func() {
+			if err := serveAdmin(ListenerConfig{Address: config.Admin.Listen, SocketMode: config.Admin.SocketMode}); err != nil {
+				log.Fatalf("failed to serve the admin service: %v", err)
+			}
+		}()
+	}
 	Serve()
 }
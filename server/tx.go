@@ -0,0 +1,397 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	types "grpc_server4/proto/generated"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmquery "github.com/tendermint/tendermint/libs/pubsub/query"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	anypb "google.golang.org/protobuf/types/known/anypb"
+)
+
+// TX_PAGE_LIMIT is the page size used when walking upstream tx search results, and the largest SearchTxs page
+const TX_PAGE_LIMIT = 100
+
+// SEARCH_DEFAULT_LIMIT is the SearchTxs page size used when the request does not set one
+const SEARCH_DEFAULT_LIMIT = 30
+
+// GetBlockTxs returns the transactions of the block at the given height decoded with the Osmosis codec
+func (s *server) GetBlockTxs(ctx context.Context, req *types.GetBlockTxsRequest) (*types.GetBlockTxsResponse, error) {
+	grpcConn, _ := grpc.Dial(
+		chainFromContext(ctx).GRPCAddress, // your gRPC server address.
+		grpc.WithInsecure(),
+		upstreamCallOptions(),
+	)
+	defer func(grpcConn *grpc.ClientConn) {
+		err := grpcConn.Close()
+		if err != nil {
+			fmt.Println("grpcConn.Close() err:", err)
+		}
+	}(grpcConn)
+	client := tmservice.NewServiceClient(grpcConn)
+	block, err := client.GetBlockByHeight(ctx, &tmservice.GetBlockByHeightRequest{
+		Height: req.Height,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &types.GetBlockTxsResponse{
+		Height: block.Block.Header.Height,
+		Txs:    chainFromContext(ctx).decodeTxs(block.Block.Data.Txs),
+	}, nil
+}
+
+// GetTx returns the decoded tx with the given hash together with its execution result
+func (s *server) GetTx(ctx context.Context, req *types.GetTxRequest) (*types.GetTxResponse, error) {
+	hash := strings.ToUpper(strings.TrimPrefix(req.Hash, "0x"))
+	if hash == "" {
+		return nil, status.Error(codes.InvalidArgument, "tx hash is required")
+	}
+	grpcConn, _ := grpc.Dial(
+		chainFromContext(ctx).GRPCAddress, // your gRPC server address.
+		grpc.WithInsecure(),
+		upstreamCallOptions(),
+	)
+	defer func(grpcConn *grpc.ClientConn) {
+		err := grpcConn.Close()
+		if err != nil {
+			fmt.Println("grpcConn.Close() err:", err)
+		}
+	}(grpcConn)
+	client := txtypes.NewServiceClient(grpcConn)
+	resp, err := client.GetTx(ctx, &txtypes.GetTxRequest{Hash: hash})
+	if err != nil {
+		return nil, err
+	}
+	return &types.GetTxResponse{
+		Tx: chainFromContext(ctx).txResult(resp.Tx, resp.TxResponse),
+	}, nil
+}
+
+// GetTxsByHeight returns every decoded tx of the block at the given height together with its execution result
+func (s *server) GetTxsByHeight(ctx context.Context, req *types.GetTxsByHeightRequest) (*types.GetTxsByHeightResponse, error) {
+	if req.Height <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "height must be positive")
+	}
+	grpcConn, _ := grpc.Dial(
+		chainFromContext(ctx).GRPCAddress, // your gRPC server address.
+		grpc.WithInsecure(),
+		upstreamCallOptions(),
+	)
+	defer func(grpcConn *grpc.ClientConn) {
+		err := grpcConn.Close()
+		if err != nil {
+			fmt.Println("grpcConn.Close() err:", err)
+		}
+	}(grpcConn)
+	client := txtypes.NewServiceClient(grpcConn)
+	ans := &types.GetTxsByHeightResponse{
+		Height: req.Height,
+		Txs:    make([]*types.TxResult, 0, 0),
+	}
+	// walk the pages until every tx of the block has been collected
+	for {
+		resp, err := client.GetTxsEvent(ctx, &txtypes.GetTxsEventRequest{
+			Events:     []string{fmt.Sprintf("tx.height=%d", req.Height)},
+			Pagination: &query.PageRequest{Offset: uint64(len(ans.Txs)), Limit: TX_PAGE_LIMIT, CountTotal: true},
+			OrderBy:    txtypes.OrderBy_ORDER_BY_ASC,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i, txResp := range resp.TxResponses {
+			ans.Txs = append(ans.Txs, chainFromContext(ctx).txResult(resp.Txs[i], txResp))
+		}
+		if len(resp.TxResponses) == 0 || resp.Pagination == nil || uint64(len(ans.Txs)) >= resp.Pagination.Total {
+			break
+		}
+	}
+	return ans, nil
+}
+
+// SearchTxs returns the decoded txs matching a tendermint event query, proxied to the tx_search of the node at the chain RPC address.
+// The cosmos tx service only accepts key=value events, so range conditions such as tx.height>100 need the tendermint RPC.
+func (s *server) SearchTxs(ctx context.Context, req *types.SearchTxsRequest) (*types.SearchTxsResponse, error) {
+	if _, err := tmquery.New(req.Query); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid query %q: %v", req.Query, err)
+	}
+	page, limit := int(req.Page), int(req.Limit)
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = SEARCH_DEFAULT_LIMIT
+	}
+	if limit > TX_PAGE_LIMIT {
+		limit = TX_PAGE_LIMIT
+	}
+	orderBy := ""
+	switch req.OrderBy {
+	case types.OrderBy_ORDER_BY_ASC:
+		orderBy = "asc"
+	case types.OrderBy_ORDER_BY_DESC:
+		orderBy = "desc"
+	}
+	result, err := chainFromContext(ctx).RPC.TxSearch(ctx, req.Query, false, &page, &limit, orderBy)
+	if err != nil {
+		return nil, err
+	}
+	ans := &types.SearchTxsResponse{
+		Txs:        make([]*types.TxResult, 0, len(result.Txs)),
+		Page:       int32(page),
+		Limit:      int32(limit),
+		TotalCount: int64(result.TotalCount),
+	}
+	for _, tx := range result.Txs {
+		ans.Txs = append(ans.Txs, chainFromContext(ctx).searchTxResult(tx))
+	}
+	return ans, nil
+}
+
+// searchTxResult converts a tendermint tx_search result into a TxResult, decoding the tx with the codec of the chain
+func (c *Chain) searchTxResult(tx *coretypes.ResultTx) *types.TxResult {
+	return &types.TxResult{
+		Hash:      tx.Hash.String(),
+		Height:    tx.Height,
+		Tx:        c.decodeTx(tx.Tx),
+		GasWanted: tx.TxResult.GasWanted,
+		GasUsed:   tx.TxResult.GasUsed,
+		Code:      tx.TxResult.Code,
+		Codespace: tx.TxResult.Codespace,
+		RawLog:    tx.TxResult.Log,
+		Events:    events(tx.TxResult.Events),
+		Index:     tx.Index,
+	}
+}
+
+// txResult converts an upstream tx and its TxResponse into a TxResult, decoding the tx with the codec of the chain
+func (c *Chain) txResult(tx *txtypes.Tx, resp *sdk.TxResponse) *types.TxResult {
+	ans := &types.TxResult{
+		Hash:      resp.TxHash,
+		Height:    resp.Height,
+		GasWanted: resp.GasWanted,
+		GasUsed:   resp.GasUsed,
+		Code:      resp.Code,
+		Codespace: resp.Codespace,
+		RawLog:    resp.RawLog,
+		Events:    events(resp.Events),
+		Timestamp: resp.Timestamp,
+	}
+	if tx != nil {
+		// tx.Tx and the raw TxRaw bytes share the same wire format, so the TxConfig decoder accepts it
+		txBytes, err := tx.Marshal()
+		if err != nil {
+			ans.Tx = &types.DecodedTx{Hash: resp.TxHash, DecodeError: err.Error()}
+			return ans
+		}
+		ans.Tx = c.decodeTx(txBytes)
+		ans.Tx.Hash = resp.TxHash
+	}
+	return ans
+}
+
+// events converts ABCI events into their proto representation
+func events(abciEvents []abci.Event) []*types.Event {
+	ans := make([]*types.Event, 0, len(abciEvents))
+	for _, e := range abciEvents {
+		event := &types.Event{
+			Type:       e.Type,
+			Attributes: make([]*types.EventAttribute, 0, len(e.Attributes)),
+		}
+		for _, attr := range e.Attributes {
+			event.Attributes = append(event.Attributes, &types.EventAttribute{
+				Key:   string(attr.Key),
+				Value: string(attr.Value),
+				Index: attr.Index,
+			})
+		}
+		ans = append(ans, event)
+	}
+	return ans
+}
+
+// SIGNER_FIELDS are the fields of the sdk, ibc and osmosis messages holding their signer, the first one set is used.
+// A validator operator address signs as the account with the same bytes.
+var SIGNER_FIELDS = []string{
+	"signer", "sender", "from_address", "delegator_address", "granter", "grantee", "voter", "depositor", "proposer",
+	"submitter", "owner", "validator_address", "validator_addr",
+}
+
+// txSigners returns the signers of the messages of a tx, then its fee payer when it is not one of them, like the
+// sdk does. A proposer can put a tx whose signer addresses do not parse in a block.
+func (c *Chain) txSigners(msgs []sdk.Msg, fee *txtypes.Fee) ([]sdk.AccAddress, error) {
+	var signers []sdk.AccAddress
+	seen := make(map[string]bool)
+	for _, msg := range msgs {
+		msgSigners, err := c.msgSigners(msg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signer: %w", err)
+		}
+		for _, signer := range msgSigners {
+			if !seen[string(signer)] {
+				signers = append(signers, signer)
+				seen[string(signer)] = true
+			}
+		}
+	}
+	if fee.Payer != "" {
+		payer, err := c.fromBech32(c.Bech32Prefix, fee.Payer)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fee payer: %w", err)
+		}
+		if !seen[string(payer)] {
+			signers = append(signers, payer)
+		}
+	}
+	return signers, nil
+}
+
+// msgSigners reads the signers of msg from its SIGNER_FIELDS, or the inputs of a multi-send, with the bech32
+// prefixes of the chain. msg.GetSigners would parse them with the prefix of the global sdk config instead.
+func (c *Chain) msgSigners(msg sdk.Msg) (signers []sdk.AccAddress, err error) {
+	bz, err := c.Context.Codec.MarshalJSON(msg)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(bz, &fields); err != nil {
+		return nil, err
+	}
+	var inputs []struct{ Address string }
+	if raw, ok := fields["inputs"]; ok {
+		if err := json.Unmarshal(raw, &inputs); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range SIGNER_FIELDS {
+		var address string
+		if raw, ok := fields[name]; ok && json.Unmarshal(raw, &address) == nil && address != "" {
+			inputs = []struct{ Address string }{{address}}
+			break
+		}
+	}
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("%s has no signer field", sdk.MsgTypeURL(msg))
+	}
+	for _, input := range inputs {
+		signer, err := c.fromBech32(c.Bech32Prefix, input.Address)
+		if err != nil {
+			var valErr error
+			if signer, valErr = c.fromBech32(c.valPrefix(), input.Address); valErr != nil {
+				return nil, err
+			}
+		}
+		signers = append(signers, signer)
+	}
+	return signers, nil
+}
+
+// decodeFee returns the fee of a tx with its signers, paid by its fee payer or else its first signer
+func (c *Chain) decodeFee(fee *txtypes.Fee, signers []sdk.AccAddress) (*types.Fee, error) {
+	ans := &types.Fee{
+		Amount:   make([]*types.Coin, 0, len(fee.Amount)),
+		GasLimit: fee.GasLimit,
+	}
+	for _, coin := range fee.Amount {
+		ans.Amount = append(ans.Amount, &types.Coin{
+			Denom:  coin.Denom,
+			Amount: coin.Amount.String(),
+		})
+	}
+	switch {
+	case fee.Payer != "":
+		// checked by txSigners
+		payer, _ := c.fromBech32(c.Bech32Prefix, fee.Payer)
+		ans.Payer = c.accAddress(payer)
+	case len(signers) > 0:
+		ans.Payer = c.accAddress(signers[0])
+	default:
+		return nil, fmt.Errorf("tx has no signer to pay the fee")
+	}
+	if fee.Granter != "" {
+		granter, err := c.fromBech32(c.Bech32Prefix, fee.Granter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fee granter: %w", err)
+		}
+		ans.Granter = c.accAddress(granter)
+	}
+	return ans, nil
+}
+
+// decodeTxs decodes every raw tx of a block, keeping the block order
+func (c *Chain) decodeTxs(txs [][]byte) []*types.DecodedTx {
+	decoded := make([]*types.DecodedTx, 0, len(txs))
+	for _, txBytes := range txs {
+		decoded = append(decoded, c.decodeTx(txBytes))
+	}
+	return decoded
+}
+
+// decodeTx decodes raw tx bytes with the TxConfig of the chain into messages, fee, memo and signers.
+// A tx that cannot be decoded is returned with only its hash and DecodeError set.
+func (c *Chain) decodeTx(txBytes []byte) *types.DecodedTx {
+	ans := &types.DecodedTx{
+		Hash: fmt.Sprintf("%X", tmtypes.Tx(txBytes).Hash()),
+	}
+	sdkTx, err := c.Context.TxConfig.TxDecoder()(txBytes)
+	if err != nil {
+		ans.DecodeError = err.Error()
+		return ans
+	}
+	for _, msg := range sdkTx.GetMsgs() {
+		msgAny, err := codectypes.NewAnyWithValue(msg)
+		if err != nil {
+			ans.DecodeError = err.Error()
+			return ans
+		}
+		ans.Messages = append(ans.Messages, &anypb.Any{
+			TypeUrl: msgAny.TypeUrl,
+			Value:   msgAny.Value,
+		})
+	}
+	if tx, ok := sdkTx.(authsigning.Tx); ok {
+		ans.Memo = tx.GetMemo()
+		ans.TimeoutHeight = tx.GetTimeoutHeight()
+		// the getters of the sdk tx assume a fee and a signer, which the decoder does not check, and panic on an
+		// address they cannot parse, so the proto tx is read instead
+		protoTx, ok := tx.(interface{ GetProtoTx() *txtypes.Tx })
+		if !ok || protoTx.GetProtoTx().GetAuthInfo().GetFee() == nil {
+			ans.DecodeError = "tx has no fee"
+			return ans
+		}
+		fee := protoTx.GetProtoTx().AuthInfo.Fee
+		signers, err := c.txSigners(protoTx.GetProtoTx().GetMsgs(), fee)
+		if err != nil {
+			ans.DecodeError = err.Error()
+			return ans
+		}
+		for _, signer := range signers {
+			ans.Signers = append(ans.Signers, c.accAddress(signer))
+		}
+		if ans.Fee, err = c.decodeFee(fee, signers); err != nil {
+			ans.DecodeError = err.Error()
+			return ans
+		}
+	}
+	jsonBytes, err := c.Context.TxConfig.TxJSONEncoder()(sdkTx)
+	if err != nil {
+		ans.DecodeError = err.Error()
+		return ans
+	}
+	ans.Json = string(jsonBytes)
+	return ans
+}
@@ -0,0 +1,223 @@
+// This file contains tests for the transaction decoding used by GetBlockTxs and the decode_txs block option.
+//
+// The transactions are built locally with the Osmosis TxConfig, so these tests do not need a running node.
+package main
+
+import (
+	"context"
+	types "grpc_server4/proto/generated"
+	"testing"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// buildTestTx encodes a MsgSend tx from the given address with the TxConfig and bech32 prefix of chain
+func buildTestTx(t testing.TB, chain *Chain, from sdk.AccAddress) []byte {
+	to := sdk.AccAddress([]byte("to__________________"))
+	builder := chain.Context.TxConfig.NewTxBuilder()
+	err := builder.SetMsgs(&banktypes.MsgSend{
+		FromAddress: chain.accAddress(from),
+		ToAddress:   chain.accAddress(to),
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("uosmo", 1000)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder.SetMemo("test memo")
+	builder.SetFeeAmount(sdk.NewCoins(sdk.NewInt64Coin("uosmo", 25)))
+	builder.SetGasLimit(200000)
+	txBytes, err := chain.Context.TxConfig.TxEncoder()(builder.GetTx())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return txBytes
+}
+
+// TestDecodeTx tests that a tx encoded with the Osmosis TxConfig is decoded back into its parts
+func TestDecodeTx(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	from := sdk.AccAddress([]byte("from________________"))
+	ans := DefaultChain.decodeTx(buildTestTx(t, DefaultChain, from))
+	if ans.DecodeError != "" {
+		t.Fatalf("decode error: %s", ans.DecodeError)
+	}
+	if len(ans.Hash) != 64 {
+		t.Errorf("unexpected hash %q", ans.Hash)
+	}
+	if len(ans.Messages) != 1 || ans.Messages[0].TypeUrl != "/cosmos.bank.v1beta1.MsgSend" {
+		t.Errorf("unexpected messages %v", ans.Messages)
+	}
+	if ans.Memo != "test memo" {
+		t.Errorf("unexpected memo %q", ans.Memo)
+	}
+	if ans.Fee.GasLimit != 200000 || len(ans.Fee.Amount) != 1 || ans.Fee.Amount[0].Amount != "25" {
+		t.Errorf("unexpected fee %v", ans.Fee)
+	}
+	if len(ans.Signers) != 1 || ans.Signers[0] != from.String() {
+		t.Errorf("unexpected signers %v", ans.Signers)
+	}
+	if ans.Json == "" {
+		t.Error("empty json")
+	}
+}
+
+// TestDecodeTxInvalid tests that undecodable bytes still return the tx hash with a decode error
+func TestDecodeTxInvalid(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	ans := DefaultChain.decodeTx([]byte("not a tx"))
+	if ans.DecodeError == "" {
+		t.Error("expected a decode error")
+	}
+	if ans.Hash == "" {
+		t.Error("expected the tx hash")
+	}
+}
+
+// TestDecodeTxMalformed tests that the txs the decoder accepts without a body, a fee or signers, which a proposer
+// can put in a block, are reported instead of panicking
+func TestDecodeTxMalformed(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	from := sdk.AccAddress([]byte("from________________"))
+	msg, err := codectypes.NewAnyWithValue(banktypes.NewMsgSend(from, from, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	badSigner, err := codectypes.NewAnyWithValue(&banktypes.MsgSend{FromAddress: "not an address", ToAddress: from.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, raw := range map[string]*txtypes.TxRaw{
+		"empty":       {},
+		"no signer":   {AuthInfoBytes: mustMarshal(t, &txtypes.AuthInfo{Fee: &txtypes.Fee{}})},
+		"no fee":      {BodyBytes: mustMarshal(t, &txtypes.TxBody{Messages: []*codectypes.Any{msg}})},
+		"bad payer":   {BodyBytes: mustMarshal(t, &txtypes.TxBody{Messages: []*codectypes.Any{msg}}), AuthInfoBytes: mustMarshal(t, &txtypes.AuthInfo{Fee: &txtypes.Fee{Payer: "osmo1bad"}})},
+		"bad signer":  {BodyBytes: mustMarshal(t, &txtypes.TxBody{Messages: []*codectypes.Any{badSigner}}), AuthInfoBytes: mustMarshal(t, &txtypes.AuthInfo{Fee: &txtypes.Fee{}})},
+		"bad granter": {BodyBytes: mustMarshal(t, &txtypes.TxBody{Messages: []*codectypes.Any{msg}}), AuthInfoBytes: mustMarshal(t, &txtypes.AuthInfo{Fee: &txtypes.Fee{Granter: "cosmos1bad"}})},
+	} {
+		txBytes, err := raw.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ans := DefaultChain.decodeTx(txBytes); ans.DecodeError == "" || ans.Hash == "" {
+			t.Errorf("%s: expected the hash and a decode error, got %v", name, ans)
+		}
+	}
+}
+
+// mustMarshal encodes a proto message of a test tx
+func mustMarshal(t *testing.T, msg interface{ Marshal() ([]byte, error) }) []byte {
+	bz, err := msg.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bz
+}
+
+// TestMsgSigners tests that the signers are read from the signer fields of the messages with the chain prefixes
+func TestMsgSigners(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	a, b := sdk.AccAddress([]byte("a___________________")), sdk.AccAddress([]byte("b___________________"))
+	c := DefaultChain
+	for name, test := range map[string]struct {
+		msg     sdk.Msg
+		signers []sdk.AccAddress
+	}{
+		"send":     {&banktypes.MsgSend{FromAddress: c.accAddress(a), ToAddress: c.accAddress(b)}, []sdk.AccAddress{a}},
+		"delegate": {&stakingtypes.MsgDelegate{DelegatorAddress: c.accAddress(b), ValidatorAddress: c.valAddress(a)}, []sdk.AccAddress{b}},
+		"operator": {&stakingtypes.MsgEditValidator{ValidatorAddress: c.valAddress(a)}, []sdk.AccAddress{a}},
+		"multi-send": {&banktypes.MsgMultiSend{Inputs: []banktypes.Input{
+			{Address: c.accAddress(a)}, {Address: c.accAddress(b)},
+		}}, []sdk.AccAddress{a, b}},
+	} {
+		signers, err := c.msgSigners(test.msg)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if len(signers) != len(test.signers) || !signers[0].Equals(test.signers[0]) || !signers[len(signers)-1].Equals(test.signers[len(test.signers)-1]) {
+			t.Errorf("%s: expected the signers %v, got %v", name, test.signers, signers)
+		}
+	}
+}
+
+// TestTxResult tests that an upstream tx and TxResponse are converted into a decoded TxResult
+func TestTxResult(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	from := sdk.AccAddress([]byte("from________________"))
+	tx := &txtypes.Tx{}
+	if err := tx.Unmarshal(buildTestTx(t, DefaultChain, from)); err != nil {
+		t.Fatal(err)
+	}
+	resp := &sdk.TxResponse{
+		Height:  8700000,
+		TxHash:  "ABCD",
+		GasUsed: 100,
+		Events: []abci.Event{{
+			Type:       "transfer",
+			Attributes: []abci.EventAttribute{{Key: []byte("sender"), Value: []byte(from.String()), Index: true}},
+		}},
+	}
+
+	ans := DefaultChain.txResult(tx, resp)
+	if ans.Height != 8700000 || ans.GasUsed != 100 || ans.Hash != "ABCD" {
+		t.Errorf("unexpected result %v", ans)
+	}
+	if ans.Tx.DecodeError != "" || ans.Tx.Hash != "ABCD" || ans.Tx.Memo != "test memo" {
+		t.Errorf("unexpected tx %v", ans.Tx)
+	}
+	if len(ans.Events) != 1 || ans.Events[0].Attributes[0].Value != from.String() || !ans.Events[0].Attributes[0].Index {
+		t.Errorf("unexpected events %v", ans.Events)
+	}
+}
+
+// TestSearchTxsInvalidQuery tests that a malformed event query is rejected before reaching the upstream node
+func TestSearchTxsInvalidQuery(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	s := &server{}
+	_, err := s.SearchTxs(context.Background(), &types.SearchTxsRequest{Query: "message.sender=="})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
+
+// TestSearchTxResult tests that a tendermint tx_search result is converted into a decoded TxResult
+func TestSearchTxResult(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	txBytes := buildTestTx(t, DefaultChain, sdk.AccAddress([]byte("from________________")))
+	ans := DefaultChain.searchTxResult(&coretypes.ResultTx{
+		Hash:     tmtypes.Tx(txBytes).Hash(),
+		Height:   101,
+		Index:    2,
+		TxResult: abci.ResponseDeliverTx{Code: 5, Log: "out of gas", GasUsed: 10},
+		Tx:       txBytes,
+	})
+	if ans.Height != 101 || ans.Index != 2 || ans.Code != 5 || ans.RawLog != "out of gas" {
+		t.Errorf("unexpected result %v", ans)
+	}
+	if ans.Hash != ans.Tx.Hash || ans.Tx.Memo != "test memo" {
+		t.Errorf("unexpected tx %v", ans.Tx)
+	}
+}
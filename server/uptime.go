@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	types "grpc_server4/proto/generated"
+	"sort"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MAX_BLOCK_RANGE is the largest number of heights a single block walking report may cover
+const MAX_BLOCK_RANGE = 1000
+
+// DEFAULT_MISSED_BLOCKS_WINDOW is the number of heights GetMissedBlocks reports when the request does not set a window
+const DEFAULT_MISSED_BLOCKS_WINDOW = 100
+
+// GetValidatorUptime returns the signed and missed blocks of a single validator between two heights
+func (s *server) GetValidatorUptime(ctx context.Context, req *types.GetValidatorUptimeRequest) (*types.GetValidatorUptimeResponse, error) {
+	if err := checkBlockRange(req.FromHeight, req.ToHeight); err != nil {
+		return nil, err
+	}
+	grpcConn, _ := grpc.Dial(
+		chainFromContext(ctx).GRPCAddress, // your gRPC server address.
+		grpc.WithInsecure(),
+		upstreamCallOptions(),
+	)
+	defer func(grpcConn *grpc.ClientConn) {
+		err := grpcConn.Close()
+		if err != nil {
+			fmt.Println("grpcConn.Close() err:", err)
+		}
+	}(grpcConn)
+	chain := chainFromContext(ctx)
+	staking := stakingtypes.NewQueryClient(grpcConn)
+	var ops map[string]*types.OperatorInfo
+	if strings.HasPrefix(req.Validator, chain.valPrefix()+"1") {
+		// an operator address is resolved through the staking state, which is required then
+		var err error
+		if ops, err = operators(atStateHeight(ctx, req.ToHeight), staking); err != nil {
+			return nil, err
+		}
+	} else {
+		ops = operatorsAt(ctx, staking, req.ToHeight)
+	}
+	address, err := chain.consensusAddress(req.Validator, ops)
+	if err != nil {
+		return nil, err
+	}
+	report, err := uptimeReport(ctx, tmservice.NewServiceClient(grpcConn), req.FromHeight, req.ToHeight, req.VerifyCommits)
+	if err != nil {
+		return nil, err
+	}
+	uptime, ok := report[address]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "validator %s is not in the validator-set between heights %d and %d", address, req.FromHeight, req.ToHeight)
+	}
+	uptime.Operator = ops[address]
+	return &types.GetValidatorUptimeResponse{
+		FromHeight: req.FromHeight,
+		ToHeight:   req.ToHeight,
+		Uptime:     uptime,
+	}, nil
+}
+
+// GetMissedBlocks returns the signed and missed blocks of every validator over a window of heights,
+// by default the last heights whose commit is already in a block
+func (s *server) GetMissedBlocks(ctx context.Context, req *types.GetMissedBlocksRequest) (*types.GetMissedBlocksResponse, error) {
+	grpcConn, _ := grpc.Dial(
+		chainFromContext(ctx).GRPCAddress, // your gRPC server address.
+		grpc.WithInsecure(),
+		upstreamCallOptions(),
+	)
+	defer func(grpcConn *grpc.ClientConn) {
+		err := grpcConn.Close()
+		if err != nil {
+			fmt.Println("grpcConn.Close() err:", err)
+		}
+	}(grpcConn)
+	client := tmservice.NewServiceClient(grpcConn)
+	from, to := req.FromHeight, req.ToHeight
+	if from == 0 && to == 0 {
+		window := req.Window
+		if window <= 0 {
+			window = DEFAULT_MISSED_BLOCKS_WINDOW
+		}
+		latest, err := client.GetLatestBlock(ctx, &tmservice.GetLatestBlockRequest{})
+		if err != nil {
+			return nil, err
+		}
+		// the commit for the latest height is only known once the next block is produced
+		to = latest.Block.Header.Height - 1
+		from = to - window + 1
+		if from < 1 {
+			from = 1
+		}
+	}
+	if err := checkBlockRange(from, to); err != nil {
+		return nil, err
+	}
+	report, err := uptimeReport(ctx, client, from, to, req.VerifyCommits)
+	if err != nil {
+		return nil, err
+	}
+	ops := operatorsAt(ctx, stakingtypes.NewQueryClient(grpcConn), to)
+	ans := &types.GetMissedBlocksResponse{
+		FromHeight: from,
+		ToHeight:   to,
+		Validators: make([]*types.ValidatorUptime, 0, len(report)),
+	}
+	for address, uptime := range report {
+		uptime.Operator = ops[address]
+		ans.Validators = append(ans.Validators, uptime)
+	}
+	sort.Slice(ans.Validators, func(i, j int) bool {
+		if ans.Validators[i].Missed != ans.Validators[j].Missed {
+			return ans.Validators[i].Missed > ans.Validators[j].Missed
+		}
+		return ans.Validators[i].Address < ans.Validators[j].Address
+	})
+	return ans, nil
+}
+
+// checkBlockRange validates the heights of a block walking report
+func checkBlockRange(from, to int64) error {
+	if from <= 0 || to < from {
+		return status.Errorf(codes.InvalidArgument, "invalid height range %d to %d", from, to)
+	}
+	if to-from+1 > MAX_BLOCK_RANGE {
+		return status.Errorf(codes.InvalidArgument, "height range is limited to %d blocks", MAX_BLOCK_RANGE)
+	}
+	return nil
+}
+
+// consensusAddress resolves a bech32 consensus or validator operator address of the chain, or a hex consensus
+// address, to the consensus address used in validator-sets
+func (c *Chain) consensusAddress(validator string, ops map[string]*types.OperatorInfo) (string, error) {
+	switch {
+	case strings.HasPrefix(validator, c.consPrefix()+"1"):
+		if _, err := c.fromBech32(c.consPrefix(), validator); err != nil {
+			return "", status.Errorf(codes.InvalidArgument, "invalid validator %q: %v", validator, err)
+		}
+		return validator, nil
+	case strings.HasPrefix(validator, c.valPrefix()+"1"):
+		for address, op := range ops {
+			if op.OperatorAddress == validator {
+				return address, nil
+			}
+		}
+		return "", status.Errorf(codes.NotFound, "no staking validator with operator address %s", validator)
+	default:
+		bz, err := hex.DecodeString(validator)
+		if err != nil || len(bz) != 20 {
+			return "", status.Errorf(codes.InvalidArgument, "invalid validator %q", validator)
+		}
+		return c.consAddress(bz), nil
+	}
+}
+
+// uptimeReport tallies, for every height H from from to to, which validators of the set at H signed the LastCommit
+// carried by block H+1. With verifyCommits, the signatures of every commit are checked first and a commit that
+// does not verify fails the report.
+func uptimeReport(ctx context.Context, client tmservice.ServiceClient, from, to int64, verifyCommits bool) (map[string]*types.ValidatorUptime, error) {
+	report := make(map[string]*types.ValidatorUptime)
+	sets := newValidatorSets(client)
+	block, err := client.GetBlockByHeight(ctx, &tmservice.GetBlockByHeightRequest{Height: from})
+	if err != nil {
+		return nil, err
+	}
+	for height := from; height <= to; height++ {
+		next, err := client.GetBlockByHeight(ctx, &tmservice.GetBlockByHeightRequest{Height: height + 1})
+		if err != nil {
+			return nil, err
+		}
+		if verifyCommits {
+			tmSet, err := sets.tmAt(ctx, block.Block.Header)
+			if err != nil {
+				return nil, err
+			}
+			if v := verifyCommit(block.Block.Header, tmSet, next.Block.LastCommit); !v.Verified {
+				return nil, status.Errorf(codes.DataLoss, "commit for height %d does not verify: %s", height, v.Error)
+			}
+		}
+		set, err := sets.at(ctx, block.Block.Header)
+		if err != nil {
+			return nil, err
+		}
+		if err := chainFromContext(ctx).tallyCommit(report, set, next.Block.LastCommit, height); err != nil {
+			return nil, err
+		}
+		block = next
+	}
+	for _, uptime := range report {
+		uptime.Uptime = float64(uptime.Signed) / float64(uptime.Blocks)
+	}
+	return report, nil
+}
+
+// tallyCommit records in report whether each validator of set signed the commit for height.
+// Commit signatures are in validator-set order; absent signatures carry no address, so they are matched by index.
+func (c *Chain) tallyCommit(report map[string]*types.ValidatorUptime, set []*types.Validator, commit *tmproto.Commit, height int64) error {
+	if commit == nil || commit.Height != height {
+		return fmt.Errorf("block %d does not carry the commit for height %d", height+1, height)
+	}
+	if len(commit.Signatures) != len(set) {
+		return fmt.Errorf("commit for height %d has %d signatures for %d validators", height, len(commit.Signatures), len(set))
+	}
+	for i, sig := range commit.Signatures {
+		v := set[i]
+		if len(sig.ValidatorAddress) > 0 && c.consAddress(sig.ValidatorAddress) != v.ConsensusAddress {
+			return fmt.Errorf("commit for height %d is not in validator-set order at index %d", height, i)
+		}
+		uptime, ok := report[v.ConsensusAddress]
+		if !ok {
+			uptime = &types.ValidatorUptime{Address: v.ConsensusAddress}
+			report[v.ConsensusAddress] = uptime
+		}
+		uptime.Blocks++
+		// like the slashing module, a nil vote still counts as signed
+		if sig.BlockIdFlag == tmproto.BlockIDFlagCommit || sig.BlockIdFlag == tmproto.BlockIDFlagNil {
+			uptime.Signed++
+		} else {
+			uptime.Missed++
+			uptime.MissedHeights = append(uptime.MissedHeights, height)
+		}
+	}
+	return nil
+}
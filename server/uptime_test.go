@@ -0,0 +1,101 @@
+// This file contains tests for the LastCommit signing report behind GetValidatorUptime and GetMissedBlocks.
+package main
+
+import (
+	"context"
+	"fmt"
+	types "grpc_server4/proto/generated"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+)
+
+// testCommit builds the commit for height signed by the validators of set whose flag is not absent
+func testCommit(height int64, set []*tmservice.Validator, flags ...tmproto.BlockIDFlag) *tmproto.Commit {
+	commit := &tmproto.Commit{Height: height}
+	for i, v := range set {
+		sig := tmproto.CommitSig{BlockIdFlag: flags[i]}
+		if flags[i] != tmproto.BlockIDFlagAbsent {
+			addr, _ := sdk.ConsAddressFromBech32(v.Address)
+			sig.ValidatorAddress = addr
+		}
+		commit.Signatures = append(commit.Signatures, sig)
+	}
+	return commit
+}
+
+// TestUptimeReport tests that missed blocks are tallied per validator, including across a validator-set change
+func TestUptimeReport(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	const (
+		commit  = tmproto.BlockIDFlagCommit
+		absent  = tmproto.BlockIDFlagAbsent
+		voteNil = tmproto.BlockIDFlagNil
+	)
+	before, after := testValidatorSet(3), testValidatorSet(2)
+	client := &fakeValidatorService{
+		sets: map[int64][]*tmservice.Validator{10: before, 11: before, 12: after},
+		blocks: map[int64]*tmproto.Block{
+			10: {Header: tmproto.Header{Height: 10, ValidatorsHash: []byte("before")}},
+			11: {Header: tmproto.Header{Height: 11, ValidatorsHash: []byte("before")}, LastCommit: testCommit(10, before, commit, absent, commit)},
+			12: {Header: tmproto.Header{Height: 12, ValidatorsHash: []byte("after")}, LastCommit: testCommit(11, before, commit, absent, voteNil)},
+			13: {Header: tmproto.Header{Height: 13, ValidatorsHash: []byte("after")}, LastCommit: testCommit(12, after, absent, commit)},
+		},
+	}
+
+	report, err := uptimeReport(context.Background(), client, 10, 12, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := report[before[0].Address]
+	if first.Blocks != 3 || first.Signed != 2 || first.Missed != 1 || first.MissedHeights[0] != 12 {
+		t.Errorf("unexpected uptime %v", first)
+	}
+	second := report[before[1].Address]
+	if second.Blocks != 3 || second.Missed != 2 || len(second.MissedHeights) != 2 || second.MissedHeights[1] != 11 {
+		t.Errorf("unexpected uptime %v", second)
+	}
+	// the third validator left the set after height 11 and voted nil at 11, which counts as signed
+	third := report[before[2].Address]
+	if third.Blocks != 2 || third.Signed != 2 || third.Uptime != 1 {
+		t.Errorf("unexpected uptime %v", third)
+	}
+	// the set at 10 and 11 has the same hash, so it is fetched once
+	if len(client.requested) != 2 {
+		t.Errorf("expected 2 validator-set fetches, got %d", len(client.requested))
+	}
+}
+
+// TestTallyCommitOrder tests that a commit whose signatures do not follow the validator-set order is rejected
+func TestTallyCommitOrder(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	set := testValidatorSet(2)
+	commit := testCommit(5, []*tmservice.Validator{set[1], set[0]}, tmproto.BlockIDFlagCommit, tmproto.BlockIDFlagCommit)
+	if err := DefaultChain.tallyCommit(map[string]*types.ValidatorUptime{}, DefaultChain.validators(set), commit, 5); err == nil {
+		t.Error("expected an error for out of order signatures")
+	}
+}
+
+// TestConsensusAddress tests the resolution of the supported validator identifiers
+func TestConsensusAddress(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	consAddr := sdk.ConsAddress(testPubKey(1).Address())
+	ops := map[string]*types.OperatorInfo{consAddr.String(): {OperatorAddress: sdk.ValAddress(testPubKey(1).Address()).String()}}
+	for _, validator := range []string{consAddr.String(), ops[consAddr.String()].OperatorAddress, fmt.Sprintf("%X", consAddr.Bytes())} {
+		address, err := DefaultChain.consensusAddress(validator, ops)
+		if err != nil || address != consAddr.String() {
+			t.Errorf("consensusAddress(%q) = %q, %v", validator, address, err)
+		}
+	}
+	if _, err := DefaultChain.consensusAddress("not-a-validator", ops); err == nil {
+		t.Error("expected an error for an unknown identifier")
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	types "grpc_server4/proto/generated"
+	"sort"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DiffValidatorSets compares the complete validator sets at two heights and reports the validators added,
+// removed, and whose voting power or proposer priority changed
+func (s *server) DiffValidatorSets(ctx context.Context, req *types.DiffValidatorSetsRequest) (*types.DiffValidatorSetsResponse, error) {
+	if req.FromHeight <= 0 || req.ToHeight <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "from_height and to_height must be positive")
+	}
+	grpcConn, _ := grpc.Dial(
+		chainFromContext(ctx).GRPCAddress, // your gRPC server address.
+		grpc.WithInsecure(),
+		upstreamCallOptions(),
+	)
+	defer func(grpcConn *grpc.ClientConn) {
+		err := grpcConn.Close()
+		if err != nil {
+			fmt.Println("grpcConn.Close() err:", err)
+		}
+	}(grpcConn)
+	client := tmservice.NewServiceClient(grpcConn)
+	_, fromSet, err := completeValidatorSet(ctx, client, req.FromHeight)
+	if err != nil {
+		return nil, err
+	}
+	_, toSet, err := completeValidatorSet(ctx, client, req.ToHeight)
+	if err != nil {
+		return nil, err
+	}
+	chain := chainFromContext(ctx)
+	from, to := chain.validators(fromSet), chain.validators(toSet)
+	staking := stakingtypes.NewQueryClient(grpcConn)
+	joinOperatorsAt(ctx, staking, from, req.FromHeight)
+	joinOperatorsAt(ctx, staking, to, req.ToHeight)
+	ans := diffValidatorSets(from, to)
+	ans.FromHeight = req.FromHeight
+	ans.ToHeight = req.ToHeight
+	return ans, nil
+}
+
+// diffValidatorSets matches the validators of two sets by address and returns the added, removed and changed ones
+func diffValidatorSets(from, to []*types.Validator) *types.DiffValidatorSetsResponse {
+	ans := &types.DiffValidatorSetsResponse{
+		Added:                make([]*types.Validator, 0, 0),
+		Removed:              make([]*types.Validator, 0, 0),
+		Changed:              make([]*types.ValidatorChange, 0, 0),
+		FromTotalVotingPower: totalVotingPower(from),
+		ToTotalVotingPower:   totalVotingPower(to),
+	}
+	fromByAddress := make(map[string]*types.Validator, len(from))
+	for _, v := range from {
+		fromByAddress[v.Address] = v
+	}
+	toByAddress := make(map[string]*types.Validator, len(to))
+	for _, v := range to {
+		toByAddress[v.Address] = v
+		old, ok := fromByAddress[v.Address]
+		if !ok {
+			ans.Added = append(ans.Added, v)
+			continue
+		}
+		if old.VotingPower != v.VotingPower || old.ProposerPriority != v.ProposerPriority {
+			ans.Changed = append(ans.Changed, &types.ValidatorChange{
+				Address:              v.Address,
+				Operator:             v.Operator,
+				FromVotingPower:      old.VotingPower,
+				ToVotingPower:        v.VotingPower,
+				VotingPowerDelta:     v.VotingPower - old.VotingPower,
+				FromProposerPriority: old.ProposerPriority,
+				ToProposerPriority:   v.ProposerPriority,
+			})
+		}
+	}
+	for _, v := range from {
+		if _, ok := toByAddress[v.Address]; !ok {
+			ans.Removed = append(ans.Removed, v)
+		}
+	}
+	sort.SliceStable(ans.Changed, func(i, j int) bool {
+		return abs(ans.Changed[i].VotingPowerDelta) > abs(ans.Changed[j].VotingPowerDelta)
+	})
+	return ans
+}
+
+// abs returns the absolute value of x
+func abs(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
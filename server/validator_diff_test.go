@@ -0,0 +1,44 @@
+// This file contains tests for the validator set comparison behind DiffValidatorSets.
+package main
+
+import (
+	types "grpc_server4/proto/generated"
+	"testing"
+)
+
+// TestDiffValidatorSets tests that added, removed and changed validators are reported between two sets
+func TestDiffValidatorSets(t *testing.T) {
+	from := []*types.Validator{
+		{Address: "a", VotingPower: 100, ProposerPriority: 5},
+		{Address: "b", VotingPower: 50, ProposerPriority: -5},
+		{Address: "c", VotingPower: 10, ProposerPriority: 0},
+		{Address: "d", VotingPower: 1, ProposerPriority: 0},
+	}
+	to := []*types.Validator{
+		{Address: "a", VotingPower: 100, ProposerPriority: -20},
+		{Address: "b", VotingPower: 80, ProposerPriority: -5},
+		{Address: "c", VotingPower: 10, ProposerPriority: 0},
+		{Address: "e", VotingPower: 7, ProposerPriority: 0},
+	}
+
+	ans := diffValidatorSets(from, to)
+	if len(ans.Added) != 1 || ans.Added[0].Address != "e" {
+		t.Errorf("unexpected added %v", ans.Added)
+	}
+	if len(ans.Removed) != 1 || ans.Removed[0].Address != "d" {
+		t.Errorf("unexpected removed %v", ans.Removed)
+	}
+	if len(ans.Changed) != 2 {
+		t.Fatalf("unexpected changed %v", ans.Changed)
+	}
+	// the voting power change of b comes before the proposer priority only change of a
+	if ans.Changed[0].Address != "b" || ans.Changed[0].VotingPowerDelta != 30 {
+		t.Errorf("unexpected first change %v", ans.Changed[0])
+	}
+	if ans.Changed[1].Address != "a" || ans.Changed[1].FromProposerPriority != 5 || ans.Changed[1].ToProposerPriority != -20 {
+		t.Errorf("unexpected second change %v", ans.Changed[1])
+	}
+	if ans.FromTotalVotingPower != 161 || ans.ToTotalVotingPower != 197 {
+		t.Errorf("unexpected total voting power %d %d", ans.FromTotalVotingPower, ans.ToTotalVotingPower)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	types "grpc_server4/proto/generated"
+	"sort"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// STATS_DEFAULT_TOP_N is the number of validators summed in top_n_share when the request does not set it
+const STATS_DEFAULT_TOP_N = 10
+
+// STATS_DEFAULT_BUCKETS is the number of histogram buckets when the request does not set it
+const STATS_DEFAULT_BUCKETS = 10
+
+// STATS_MAX_BUCKETS bounds the number of histogram buckets a request can ask for
+const STATS_MAX_BUCKETS = 100
+
+// GetValidatorSetStats returns the voting power distribution of the complete validator set at the given height:
+// Nakamoto coefficients, Gini coefficient, top-N share and a power histogram
+func (s *server) GetValidatorSetStats(ctx context.Context, req *types.GetValidatorSetStatsRequest) (*types.GetValidatorSetStatsResponse, error) {
+	if req.Height < 0 || req.TopN < 0 || req.HistogramBuckets < 0 {
+		return nil, status.Error(codes.InvalidArgument, "height, top_n and histogram_buckets must not be negative")
+	}
+	if req.HistogramBuckets > STATS_MAX_BUCKETS {
+		return nil, status.Errorf(codes.InvalidArgument, "histogram_buckets must be at most %d", STATS_MAX_BUCKETS)
+	}
+	grpcConn, _ := grpc.Dial(
+		chainFromContext(ctx).GRPCAddress, // your gRPC server address.
+		grpc.WithInsecure(),
+		upstreamCallOptions(),
+	)
+	defer func(grpcConn *grpc.ClientConn) {
+		err := grpcConn.Close()
+		if err != nil {
+			fmt.Println("grpcConn.Close() err:", err)
+		}
+	}(grpcConn)
+	client := tmservice.NewServiceClient(grpcConn)
+	height, all, err := completeValidatorSet(ctx, client, req.Height)
+	if err != nil {
+		return nil, err
+	}
+	powers := make([]int64, 0, len(all))
+	for _, v := range all {
+		powers = append(powers, v.VotingPower)
+	}
+	topN, buckets := int(req.TopN), int(req.HistogramBuckets)
+	if topN == 0 {
+		topN = STATS_DEFAULT_TOP_N
+	}
+	if buckets == 0 {
+		buckets = STATS_DEFAULT_BUCKETS
+	}
+	ans := validatorSetStats(powers, topN, buckets)
+	ans.BlockHeight = height
+	return ans, nil
+}
+
+// validatorSetStats computes the decentralization metrics of a set of voting powers
+func validatorSetStats(powers []int64, topN, buckets int) *types.GetValidatorSetStatsResponse {
+	// largest validators first
+	sorted := append([]int64(nil), powers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] })
+	var total int64
+	for _, p := range sorted {
+		total += p
+	}
+	if topN > len(sorted) {
+		topN = len(sorted)
+	}
+	ans := &types.GetValidatorSetStatsResponse{
+		ValidatorCount:               int32(len(sorted)),
+		TotalVotingPower:             total,
+		NakamotoCoefficientOneThird:  int32(nakamotoCoefficient(sorted, total, 1, 3)),
+		NakamotoCoefficientTwoThirds: int32(nakamotoCoefficient(sorted, total, 2, 3)),
+		GiniCoefficient:              giniCoefficient(sorted, total),
+		TopN:                         int32(topN),
+		Histogram:                    powerHistogram(sorted, total, buckets),
+	}
+	if total > 0 {
+		var top int64
+		for _, p := range sorted[:topN] {
+			top += p
+		}
+		ans.TopNShare = float64(top) / float64(total)
+	}
+	return ans
+}
+
+// nakamotoCoefficient returns the smallest number of validators, taken from the largest, whose voting power
+// is strictly more than num/den of the total. sorted must be in descending order.
+func nakamotoCoefficient(sorted []int64, total, num, den int64) int {
+	var sum int64
+	for i, p := range sorted {
+		sum += p
+		if sum*den > total*num {
+			return i + 1
+		}
+	}
+	return len(sorted)
+}
+
+// giniCoefficient returns the Gini coefficient of the voting powers. sorted must be in descending order.
+func giniCoefficient(sorted []int64, total int64) float64 {
+	n := len(sorted)
+	if n == 0 || total == 0 {
+		return 0
+	}
+	// G = 2*sum(i*x_i)/(n*sum(x)) - (n+1)/n with x ascending and i starting at 1
+	var weighted float64
+	for i, p := range sorted {
+		weighted += float64(n-i) * float64(p)
+	}
+	return 2*weighted/(float64(n)*float64(total)) - float64(n+1)/float64(n)
+}
+
+// powerHistogram splits the voting power range of the set into equal-width buckets. sorted must be in descending order.
+func powerHistogram(sorted []int64, total int64, buckets int) []*types.PowerBucket {
+	if len(sorted) == 0 || buckets <= 0 {
+		return []*types.PowerBucket{}
+	}
+	lowest, highest := sorted[len(sorted)-1], sorted[0]
+	// the widths are rounded up, so fewer buckets may cover the span, none of them starting past highest
+	span := highest - lowest + 1
+	width := (span + int64(buckets) - 1) / int64(buckets)
+	buckets = int((span + width - 1) / width)
+	ans := make([]*types.PowerBucket, 0, buckets)
+	for i := 0; i < buckets; i++ {
+		bucket := &types.PowerBucket{
+			MinVotingPower: lowest + int64(i)*width,
+			MaxVotingPower: lowest + int64(i+1)*width - 1,
+		}
+		if i == buckets-1 {
+			bucket.MaxVotingPower = highest
+		}
+		ans = append(ans, bucket)
+	}
+	for _, p := range sorted {
+		i := int((p - lowest) / width)
+		if i >= buckets {
+			i = buckets - 1
+		}
+		ans[i].ValidatorCount++
+		if total > 0 {
+			ans[i].VotingPowerShare += float64(p) / float64(total)
+		}
+	}
+	return ans
+}
@@ -0,0 +1,105 @@
+// This file contains tests for the decentralization metrics behind GetValidatorSetStats.
+package main
+
+import (
+	"context"
+	types "grpc_server4/proto/generated"
+	"math"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestValidatorSetStats tests the Nakamoto coefficients, top-N share and histogram of a skewed set
+func TestValidatorSetStats(t *testing.T) {
+	// total 100: the largest validator alone holds more than 1/3, three are needed for more than 2/3
+	powers := []int64{5, 40, 25, 20, 10}
+
+	ans := validatorSetStats(powers, 2, 4)
+	if ans.ValidatorCount != 5 || ans.TotalVotingPower != 100 {
+		t.Errorf("unexpected totals %d %d", ans.ValidatorCount, ans.TotalVotingPower)
+	}
+	if ans.NakamotoCoefficientOneThird != 1 || ans.NakamotoCoefficientTwoThirds != 3 {
+		t.Errorf("unexpected nakamoto coefficients %d %d", ans.NakamotoCoefficientOneThird, ans.NakamotoCoefficientTwoThirds)
+	}
+	if ans.TopN != 2 || math.Abs(ans.TopNShare-0.65) > 1e-9 {
+		t.Errorf("unexpected top n share %d %f", ans.TopN, ans.TopNShare)
+	}
+	if len(ans.Histogram) != 4 || ans.Histogram[0].MinVotingPower != 5 || ans.Histogram[3].MaxVotingPower != 40 {
+		t.Fatalf("unexpected histogram %v", ans.Histogram)
+	}
+	var count int32
+	var share float64
+	for _, b := range ans.Histogram {
+		count += b.ValidatorCount
+		share += b.VotingPowerShare
+	}
+	if count != 5 || math.Abs(share-1) > 1e-9 {
+		t.Errorf("histogram covers %d validators and %f of the power", count, share)
+	}
+}
+
+// TestGiniCoefficient tests the Gini coefficient of an even and a concentrated distribution
+func TestGiniCoefficient(t *testing.T) {
+	if g := validatorSetStats([]int64{10, 10, 10, 10}, 10, 10).GiniCoefficient; math.Abs(g) > 1e-9 {
+		t.Errorf("expected 0 for an even set, got %f", g)
+	}
+	// one validator holding everything among four gives (n-1)/n
+	if g := validatorSetStats([]int64{0, 0, 0, 100}, 10, 10).GiniCoefficient; math.Abs(g-0.75) > 1e-9 {
+		t.Errorf("expected 0.75 for a concentrated set, got %f", g)
+	}
+}
+
+// TestValidatorSetStatsEmpty tests that an empty set does not divide by zero
+func TestValidatorSetStatsEmpty(t *testing.T) {
+	ans := validatorSetStats(nil, 10, 10)
+	if ans.ValidatorCount != 0 || ans.GiniCoefficient != 0 || ans.TopNShare != 0 || len(ans.Histogram) != 0 {
+		t.Errorf("unexpected stats %v", ans)
+	}
+}
+
+// TestValidatorSetStatsMaxBuckets tests that a histogram larger than STATS_MAX_BUCKETS is rejected before any call
+func TestValidatorSetStatsMaxBuckets(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	s := &server{}
+	_, err := s.GetValidatorSetStats(context.Background(), &types.GetValidatorSetStatsRequest{HistogramBuckets: math.MaxInt32})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+	// the buckets are clamped to the power span before they are allocated
+	if h := validatorSetStats([]int64{1, 2}, 10, STATS_MAX_BUCKETS).Histogram; len(h) != 2 || cap(h) != 2 {
+		t.Errorf("expected 2 buckets, got %d of capacity %d", len(h), cap(h))
+	}
+}
+
+// TestPowerHistogram tests that the buckets cover the power span without starting past the highest power, which
+// falls in the last one
+func TestPowerHistogram(t *testing.T) {
+	for _, c := range []struct {
+		powers  []int64
+		buckets int
+		bounds  [][2]int64
+	}{
+		{[]int64{10, 5, 0}, 10, [][2]int64{{0, 1}, {2, 3}, {4, 5}, {6, 7}, {8, 9}, {10, 10}}},
+		{[]int64{10, 5, 0}, 3, [][2]int64{{0, 3}, {4, 7}, {8, 10}}},
+		{[]int64{9, 0}, 5, [][2]int64{{0, 1}, {2, 3}, {4, 5}, {6, 7}, {8, 9}}},
+		{[]int64{7, 7}, 4, [][2]int64{{7, 7}}},
+	} {
+		h := powerHistogram(c.powers, 0, c.buckets)
+		if len(h) != len(c.bounds) {
+			t.Errorf("%v in %d: expected %d buckets, got %v", c.powers, c.buckets, len(c.bounds), h)
+			continue
+		}
+		for i, b := range h {
+			if b.MinVotingPower != c.bounds[i][0] || b.MaxVotingPower != c.bounds[i][1] {
+				t.Errorf("%v in %d: expected bucket %d to be %v, got %d..%d", c.powers, c.buckets, i, c.bounds[i], b.MinVotingPower, b.MaxVotingPower)
+			}
+		}
+		if last := h[len(h)-1]; last.ValidatorCount == 0 {
+			t.Errorf("%v in %d: expected the highest power in the last bucket, got %v", c.powers, c.buckets, h)
+		}
+	}
+}
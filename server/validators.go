@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	types "grpc_server4/proto/generated"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"google.golang.org/grpc"
+	anypb "google.golang.org/protobuf/types/known/anypb"
+)
+
+// VALIDATOR_PAGE_LIMIT is the page size used when walking the complete validator set, the tendermint maximum
+const VALIDATOR_PAGE_LIMIT = 100
+
+// STAKING_PAGE_LIMIT is the page size used when walking the staking module validators
+const STAKING_PAGE_LIMIT = 200
+
+// completeValidatorSet returns the block height and every validator of the set at the given height, zero meaning the latest height
+func completeValidatorSet(ctx context.Context, client tmservice.ServiceClient, height int64) (int64, []*tmservice.Validator, error) {
+	if height == 0 {
+		valSet, err := client.GetLatestValidatorSet(ctx, &tmservice.GetLatestValidatorSetRequest{
+			Pagination: &query.PageRequest{Limit: VALIDATOR_PAGE_LIMIT},
+		})
+		if err != nil {
+			return 0, nil, err
+		}
+		// the remaining pages are read at the height of the first one so a new block cannot mix two sets
+		all, err := allValidators(ctx, client, valSet.BlockHeight, valSet.Validators, valSet.Pagination.GetTotal())
+		return valSet.BlockHeight, all, err
+	}
+	valSet, err := client.GetValidatorSetByHeight(ctx, &tmservice.GetValidatorSetByHeightRequest{
+		Height:     height,
+		Pagination: &query.PageRequest{Limit: VALIDATOR_PAGE_LIMIT},
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	all, err := allValidators(ctx, client, height, valSet.Validators, valSet.Pagination.GetTotal())
+	return valSet.BlockHeight, all, err
+}
+
+// validatorSets caches the complete validator-sets of the headers seen while walking blocks.
+// Sets are keyed by the ValidatorsHash of the header, so one is only fetched when the set changes.
+type validatorSets struct {
+	client tmservice.ServiceClient
+	raw    map[string][]*tmservice.Validator
+	sets   map[string][]*types.Validator
+	tmSets map[string]*tmtypes.ValidatorSet
+}
+
+// newValidatorSets returns an empty validator-set cache reading from client
+func newValidatorSets(client tmservice.ServiceClient) *validatorSets {
+	return &validatorSets{
+		client: client,
+		raw:    make(map[string][]*tmservice.Validator),
+		sets:   make(map[string][]*types.Validator),
+		tmSets: make(map[string]*tmtypes.ValidatorSet),
+	}
+}
+
+// fetch returns the upstream validator-set that signs the block with the given header
+func (c *validatorSets) fetch(ctx context.Context, header tmproto.Header) ([]*tmservice.Validator, error) {
+	if all, ok := c.raw[string(header.ValidatorsHash)]; ok {
+		return all, nil
+	}
+	_, all, err := completeValidatorSet(ctx, c.client, header.Height)
+	if err != nil {
+		return nil, err
+	}
+	c.raw[string(header.ValidatorsHash)] = all
+	return all, nil
+}
+
+// at returns the validator-set that signs the block with the given header
+func (c *validatorSets) at(ctx context.Context, header tmproto.Header) ([]*types.Validator, error) {
+	if set, ok := c.sets[string(header.ValidatorsHash)]; ok {
+		return set, nil
+	}
+	all, err := c.fetch(ctx, header)
+	if err != nil {
+		return nil, err
+	}
+	set := chainFromContext(ctx).validators(all)
+	c.sets[string(header.ValidatorsHash)] = set
+	return set, nil
+}
+
+// tmAt returns the validator-set that signs the block with the given header as a tendermint validator-set
+func (c *validatorSets) tmAt(ctx context.Context, header tmproto.Header) (*tmtypes.ValidatorSet, error) {
+	if set, ok := c.tmSets[string(header.ValidatorsHash)]; ok {
+		return set, nil
+	}
+	all, err := c.fetch(ctx, header)
+	if err != nil {
+		return nil, err
+	}
+	set, err := chainFromContext(ctx).tmValidatorSet(all)
+	if err != nil {
+		return nil, err
+	}
+	c.tmSets[string(header.ValidatorsHash)] = set
+	return set, nil
+}
+
+// allValidators walks the validator set pages at the given height, starting after the validators already fetched,
+// until total validators have been collected.
+// The upstream tmservice pages validators by offset and only reports the total, it never sets a NextKey.
+func allValidators(ctx context.Context, client tmservice.ServiceClient, height int64, validators []*tmservice.Validator, total uint64) ([]*tmservice.Validator, error) {
+	for uint64(len(validators)) < total {
+		valSet, err := client.GetValidatorSetByHeight(ctx, &tmservice.GetValidatorSetByHeightRequest{
+			Height:     height,
+			Pagination: &query.PageRequest{Offset: uint64(len(validators)), Limit: VALIDATOR_PAGE_LIMIT},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(valSet.Validators) == 0 {
+			break
+		}
+		validators = append(validators, valSet.Validators...)
+	}
+	return validators, nil
+}
+
+// validators converts the upstream validators into their proto representation,
+// adding the bech32 consensus address and the raw pub key decoded with the codec of the chain
+func (c *Chain) validators(vs []*tmservice.Validator) []*types.Validator {
+	ans := make([]*types.Validator, 0, len(vs))
+	for _, v := range vs {
+		validator := &types.Validator{
+			Address: v.Address,
+			PubKey: &anypb.Any{
+				TypeUrl: v.PubKey.TypeUrl,
+				Value:   v.PubKey.Value,
+			},
+			VotingPower:      v.VotingPower,
+			ProposerPriority: v.ProposerPriority,
+		}
+		if pk, err := c.decodePubKey(v.PubKey); err == nil {
+			validator.ConsensusAddress = c.consAddress(pk.Address())
+			validator.PubKeyHex = fmt.Sprintf("%X", pk.Bytes())
+			validator.PubKeyBase64 = base64.StdEncoding.EncodeToString(pk.Bytes())
+		}
+		ans = append(ans, validator)
+	}
+	return ans
+}
+
+// decodePubKey unpacks a consensus pub key with the interface registry of the chain
+func (c *Chain) decodePubKey(pkAny *codectypes.Any) (cryptotypes.PubKey, error) {
+	var pk cryptotypes.PubKey
+	if err := c.Context.InterfaceRegistry.UnpackAny(pkAny, &pk); err != nil {
+		return nil, err
+	}
+	// Address panics on keys of the wrong size, so reject them here
+	switch key := pk.(type) {
+	case *ed25519.PubKey:
+		if len(key.Key) != ed25519.PubKeySize {
+			return nil, fmt.Errorf("invalid ed25519 pub key size %d", len(key.Key))
+		}
+	case *secp256k1.PubKey:
+		if len(key.Key) != secp256k1.PubKeySize {
+			return nil, fmt.Errorf("invalid secp256k1 pub key size %d", len(key.Key))
+		}
+	}
+	return pk, nil
+}
+
+// operators walks the staking module validators of every bond status and indexes their operator info by bech32 consensus address
+func operators(ctx context.Context, client stakingtypes.QueryClient) (map[string]*types.OperatorInfo, error) {
+	chain := chainFromContext(ctx)
+	ans := make(map[string]*types.OperatorInfo)
+	var nextKey []byte
+	for {
+		var resp *stakingtypes.QueryValidatorsResponse
+		err := stateQuery(ctx, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+			resp, err = client.Validators(ctx, &stakingtypes.QueryValidatorsRequest{
+				Pagination: &query.PageRequest{Key: nextKey, Limit: STAKING_PAGE_LIMIT},
+			}, opts...)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range resp.Validators {
+			if err := v.UnpackInterfaces(chain.Context.InterfaceRegistry); err != nil {
+				return nil, err
+			}
+			consAddr, err := v.GetConsAddr()
+			if err != nil {
+				return nil, err
+			}
+			ans[chain.consAddress(consAddr)] = &types.OperatorInfo{
+				OperatorAddress: v.OperatorAddress,
+				Moniker:         v.Description.Moniker,
+				Identity:        v.Description.Identity,
+				Website:         v.Description.Website,
+				Details:         v.Description.Details,
+				Jailed:          v.Jailed,
+				Status:          v.Status.String(),
+				Tokens:          v.Tokens.String(),
+				CommissionRate:  v.Commission.CommissionRates.Rate.String(),
+			}
+		}
+		if resp.Pagination == nil || len(resp.Pagination.NextKey) == 0 {
+			break
+		}
+		nextKey = resp.Pagination.NextKey
+	}
+	return ans, nil
+}
+
+// joinOperators sets the staking operator info of every validator whose consensus address is known
+func joinOperators(vs []*types.Validator, ops map[string]*types.OperatorInfo) {
+	for _, v := range vs {
+		v.Operator = ops[v.ConsensusAddress]
+	}
+}
+
+// joinOperatorsAt joins the staking operators to the validators of the set at height, reading the staking state at
+// height unless the request pins another one. The join is best-effort: on error the operators are left unset.
+func joinOperatorsAt(ctx context.Context, client stakingtypes.QueryClient, vs []*types.Validator, height int64) {
+	joinOperators(vs, operatorsAt(ctx, client, height))
+}
+
+// operatorsAt reads the staking operators at height unless the request pins another one. The read is best-effort:
+// on error it is logged and no operators are returned.
+func operatorsAt(ctx context.Context, client stakingtypes.QueryClient, height int64) map[string]*types.OperatorInfo {
+	ops, err := operators(atStateHeight(ctx, height), client)
+	if err != nil {
+		logf("warn", "%s staking operators at height %d: %v", chainFromContext(ctx).ChainID, height, err)
+		return nil
+	}
+	return ops
+}
+
+// totalVotingPower sums the voting power of the given validators
+func totalVotingPower(vs []*types.Validator) int64 {
+	var total int64
+	for _, v := range vs {
+		total += v.VotingPower
+	}
+	return total
+}
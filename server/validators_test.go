@@ -0,0 +1,227 @@
+// This file contains tests for the validator set helpers behind GetLatestValidatorSet and GetValidatorSetByHeight.
+//
+// The upstream tmservice is replaced by fakeValidatorService, which pages a fixed validator set the way tendermint does,
+// and the staking module by fakeStakingService.
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"google.golang.org/grpc"
+)
+
+// fakeValidatorService serves validator sets per height by offset pages and records the heights that were requested.
+// It also serves the blocks it is given, for the tests of the block walking reports.
+type fakeValidatorService struct {
+	tmservice.ServiceClient
+	latest    int64
+	sets      map[int64][]*tmservice.Validator
+	blocks    map[int64]*tmproto.Block
+	ids       map[int64]*tmproto.BlockID
+	requested []int64
+}
+
+func (f *fakeValidatorService) GetBlockByHeight(ctx context.Context, in *tmservice.GetBlockByHeightRequest, opts ...grpc.CallOption) (*tmservice.GetBlockByHeightResponse, error) {
+	block, ok := f.blocks[in.Height]
+	if !ok {
+		return nil, fmt.Errorf("no block at height %d", in.Height)
+	}
+	return &tmservice.GetBlockByHeightResponse{BlockId: f.ids[in.Height], Block: block}, nil
+}
+
+func (f *fakeValidatorService) GetLatestBlock(ctx context.Context, in *tmservice.GetLatestBlockRequest, opts ...grpc.CallOption) (*tmservice.GetLatestBlockResponse, error) {
+	return &tmservice.GetLatestBlockResponse{Block: f.blocks[f.latest]}, nil
+}
+
+func (f *fakeValidatorService) page(height int64, pagination *query.PageRequest) ([]*tmservice.Validator, *query.PageResponse, error) {
+	f.requested = append(f.requested, height)
+	set, ok := f.sets[height]
+	if !ok {
+		return nil, nil, fmt.Errorf("no validator set at height %d", height)
+	}
+	page, limit, err := query.ParsePagination(pagination)
+	if err != nil {
+		return nil, nil, err
+	}
+	start := (page - 1) * limit
+	if start > len(set) {
+		start = len(set)
+	}
+	end := start + limit
+	if end > len(set) {
+		end = len(set)
+	}
+	return set[start:end], &query.PageResponse{Total: uint64(len(set))}, nil
+}
+
+func (f *fakeValidatorService) GetLatestValidatorSet(ctx context.Context, in *tmservice.GetLatestValidatorSetRequest, opts ...grpc.CallOption) (*tmservice.GetLatestValidatorSetResponse, error) {
+	vs, pagination, err := f.page(f.latest, in.Pagination)
+	if err != nil {
+		return nil, err
+	}
+	return &tmservice.GetLatestValidatorSetResponse{BlockHeight: f.latest, Validators: vs, Pagination: pagination}, nil
+}
+
+func (f *fakeValidatorService) GetValidatorSetByHeight(ctx context.Context, in *tmservice.GetValidatorSetByHeightRequest, opts ...grpc.CallOption) (*tmservice.GetValidatorSetByHeightResponse, error) {
+	vs, pagination, err := f.page(in.Height, in.Pagination)
+	if err != nil {
+		return nil, err
+	}
+	return &tmservice.GetValidatorSetByHeightResponse{BlockHeight: in.Height, Validators: vs, Pagination: pagination}, nil
+}
+
+// fakeStakingService serves the given staking validators in pages of one, linked by NextKey
+type fakeStakingService struct {
+	stakingtypes.QueryClient
+	validators []stakingtypes.Validator
+}
+
+func (f *fakeStakingService) Validators(ctx context.Context, in *stakingtypes.QueryValidatorsRequest, opts ...grpc.CallOption) (*stakingtypes.QueryValidatorsResponse, error) {
+	i := 0
+	if len(in.Pagination.Key) > 0 {
+		i = int(in.Pagination.Key[0])
+	}
+	resp := &stakingtypes.QueryValidatorsResponse{
+		Validators: f.validators[i : i+1],
+		Pagination: &query.PageResponse{},
+	}
+	if i+1 < len(f.validators) {
+		resp.Pagination.NextKey = []byte{byte(i + 1)}
+	}
+	return resp, nil
+}
+
+// testPubKey returns a deterministic ed25519 consensus key for the i-th test validator
+func testPubKey(i int) cryptotypes.PubKey {
+	return ed25519.GenPrivKeyFromSecret([]byte(fmt.Sprintf("validator-%d", i))).PubKey()
+}
+
+// testValidatorSet builds n validators with voting power 1..n
+func testValidatorSet(n int) []*tmservice.Validator {
+	vs := make([]*tmservice.Validator, 0, n)
+	for i := 1; i <= n; i++ {
+		pkAny, err := codectypes.NewAnyWithValue(testPubKey(i))
+		if err != nil {
+			panic(err)
+		}
+		vs = append(vs, &tmservice.Validator{
+			Address:     sdk.ConsAddress(testPubKey(i).Address()).String(),
+			PubKey:      pkAny,
+			VotingPower: int64(i),
+		})
+	}
+	return vs
+}
+
+// TestCompleteValidatorSet tests that every page of a validator set larger than one page is collected
+func TestCompleteValidatorSet(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	client := &fakeValidatorService{sets: map[int64][]*tmservice.Validator{10: testValidatorSet(250)}}
+
+	height, all, err := completeValidatorSet(context.Background(), client, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height != 10 || len(all) != 250 || all[249].VotingPower != 250 {
+		t.Errorf("unexpected validator set at %d: %d validators", height, len(all))
+	}
+	if len(client.requested) != 3 {
+		t.Errorf("expected 3 pages, got %d", len(client.requested))
+	}
+	if total := totalVotingPower(DefaultChain.validators(all)); total != 250*251/2 {
+		t.Errorf("unexpected total voting power %d", total)
+	}
+}
+
+// TestCompleteLatestValidatorSet tests that the pages after the first one are pinned to the height of the latest set
+func TestCompleteLatestValidatorSet(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	client := &fakeValidatorService{latest: 20, sets: map[int64][]*tmservice.Validator{20: testValidatorSet(150)}}
+
+	height, all, err := completeValidatorSet(context.Background(), client, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height != 20 || len(all) != 150 {
+		t.Errorf("unexpected validator set at %d: %d validators", height, len(all))
+	}
+	for _, h := range client.requested {
+		if h != 20 {
+			t.Errorf("page requested at height %d instead of 20", h)
+		}
+	}
+}
+
+// TestValidatorsBech32 tests that the consensus address and raw pub key are derived from the validator pub key
+func TestValidatorsBech32(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	vs := DefaultChain.validators(testValidatorSet(1))
+	if !strings.HasPrefix(vs[0].ConsensusAddress, "osmovalcons1") || vs[0].ConsensusAddress != vs[0].Address {
+		t.Errorf("unexpected consensus address %q", vs[0].ConsensusAddress)
+	}
+	raw := testPubKey(1).Bytes()
+	if vs[0].PubKeyHex != fmt.Sprintf("%X", raw) || vs[0].PubKeyBase64 != base64.StdEncoding.EncodeToString(raw) {
+		t.Errorf("unexpected pub key %s %s", vs[0].PubKeyHex, vs[0].PubKeyBase64)
+	}
+}
+
+// testStakingService returns a staking service of the operators of the first n test validators, one per page
+func testStakingService(t *testing.T, n int) *fakeStakingService {
+	staking := &fakeStakingService{}
+	for i := 1; i <= n; i++ {
+		pkAny, err := codectypes.NewAnyWithValue(testPubKey(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		staking.validators = append(staking.validators, stakingtypes.Validator{
+			OperatorAddress: sdk.ValAddress(testPubKey(i).Address()).String(),
+			ConsensusPubkey: pkAny,
+			Status:          stakingtypes.Bonded,
+			Tokens:          sdk.NewInt(int64(i * 1000)),
+			DelegatorShares: sdk.NewDec(int64(i * 1000)),
+			Description:     stakingtypes.Description{Moniker: fmt.Sprintf("validator-%d", i)},
+		})
+	}
+	return staking
+}
+
+// TestJoinOperators tests that staking validators from every page are joined to the validator set by consensus address
+func TestJoinOperators(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	ops, err := operators(context.Background(), testStakingService(t, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vs := DefaultChain.validators(testValidatorSet(3))
+	joinOperators(vs, ops)
+	if vs[1].Operator == nil || vs[1].Operator.Moniker != "validator-2" || vs[1].Operator.Status != "BOND_STATUS_BONDED" {
+		t.Errorf("unexpected operator %v", vs[1].Operator)
+	}
+	if !strings.HasPrefix(vs[0].Operator.OperatorAddress, "osmovaloper1") || vs[0].Operator.Tokens != "1000" {
+		t.Errorf("unexpected operator %v", vs[0].Operator)
+	}
+	if vs[2].Operator != nil {
+		t.Errorf("expected no operator for a validator unknown to staking, got %v", vs[2].Operator)
+	}
+}
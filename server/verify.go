@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	types "grpc_server4/proto/generated"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// VerifyCommit checks that the LastCommit of the block after the given height carries valid ed25519 signatures
+// of the block at height from more than 2/3 of the voting power of the validator-set at height
+func (s *server) VerifyCommit(ctx context.Context, req *types.VerifyCommitRequest) (*types.VerifyCommitResponse, error) {
+	if req.Height <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "height must be positive")
+	}
+	grpcConn, _ := grpc.Dial(
+		chainFromContext(ctx).GRPCAddress, // your gRPC server address.
+		grpc.WithInsecure(),
+		upstreamCallOptions(),
+	)
+	defer func(grpcConn *grpc.ClientConn) {
+		err := grpcConn.Close()
+		if err != nil {
+			fmt.Println("grpcConn.Close() err:", err)
+		}
+	}(grpcConn)
+	client := tmservice.NewServiceClient(grpcConn)
+	block, err := client.GetBlockByHeight(ctx, &tmservice.GetBlockByHeightRequest{Height: req.Height})
+	if err != nil {
+		return nil, err
+	}
+	// the commit for a height is only known once the next block is produced
+	next, err := client.GetBlockByHeight(ctx, &tmservice.GetBlockByHeightRequest{Height: req.Height + 1})
+	if err != nil {
+		return nil, err
+	}
+	set, err := newValidatorSets(client).tmAt(ctx, block.Block.Header)
+	if err != nil {
+		return nil, err
+	}
+	return verifyCommit(block.Block.Header, set, next.Block.LastCommit), nil
+}
+
+// verifyBlock recomputes the header hash, data hash and validators hash of a block returned by the upstream,
+// and checks its LastBlockId against the previous block. Mismatches are reported in the verification,
+// failures to fetch the validator-set or the previous block are returned as errors.
+func verifyBlock(ctx context.Context, client tmservice.ServiceClient, blockID *tmproto.BlockID, block *tmproto.Block) (*types.BlockVerification, error) {
+	ans := &types.BlockVerification{}
+	header, err := tmtypes.HeaderFromProto(&block.Header)
+	if err != nil {
+		ans.Errors = append(ans.Errors, fmt.Sprintf("invalid header: %v", err))
+		return ans, nil
+	}
+	height := header.Height
+
+	headerHash := header.Hash()
+	ans.HeaderHash = headerHash.String()
+	ans.HeaderHashOk = blockID != nil && bytes.Equal(headerHash, blockID.Hash)
+	if !ans.HeaderHashOk {
+		ans.Errors = append(ans.Errors, fmt.Sprintf("header hash %s does not match block id", ans.HeaderHash))
+	}
+
+	dataHash := txsHash(block.Data.Txs)
+	ans.DataHash = fmt.Sprintf("%X", dataHash)
+	ans.DataHashOk = bytes.Equal(dataHash, header.DataHash)
+	if !ans.DataHashOk {
+		ans.Errors = append(ans.Errors, fmt.Sprintf("data hash %s does not match header data hash %s", ans.DataHash, header.DataHash))
+	}
+
+	_, all, err := completeValidatorSet(ctx, client, height)
+	if err != nil {
+		return nil, err
+	}
+	set, err := chainFromContext(ctx).tmValidatorSet(all)
+	if err != nil {
+		ans.Errors = append(ans.Errors, fmt.Sprintf("invalid validator-set at height %d: %v", height, err))
+	} else {
+		validatorsHash := set.Hash()
+		ans.ValidatorsHash = fmt.Sprintf("%X", validatorsHash)
+		ans.ValidatorsHashOk = bytes.Equal(validatorsHash, header.ValidatorsHash)
+		if !ans.ValidatorsHashOk {
+			ans.Errors = append(ans.Errors, fmt.Sprintf("validators hash %s does not match header validators hash %s", ans.ValidatorsHash, header.ValidatorsHash))
+		}
+	}
+
+	if height <= 1 {
+		ans.LastBlockIdOk = true
+	} else {
+		prev, err := client.GetBlockByHeight(ctx, &tmservice.GetBlockByHeightRequest{Height: height - 1})
+		if err != nil {
+			return nil, err
+		}
+		prevHeader, err := tmtypes.HeaderFromProto(&prev.Block.Header)
+		if err != nil {
+			ans.Errors = append(ans.Errors, fmt.Sprintf("invalid header at height %d: %v", height-1, err))
+		} else {
+			ans.LastBlockIdOk = bytes.Equal(prevHeader.Hash(), header.LastBlockID.Hash)
+			if !ans.LastBlockIdOk {
+				ans.Errors = append(ans.Errors, fmt.Sprintf("last block id %s is not the hash of block %d", header.LastBlockID.Hash, height-1))
+			}
+		}
+	}
+
+	ans.Verified = ans.HeaderHashOk && ans.DataHashOk && ans.ValidatorsHashOk && ans.LastBlockIdOk
+	return ans, nil
+}
+
+// txsHash returns the merkle root of the block txs, as stored in Header.DataHash
+func txsHash(txs [][]byte) []byte {
+	tmTxs := make(tmtypes.Txs, 0, len(txs))
+	for _, tx := range txs {
+		tmTxs = append(tmTxs, tx)
+	}
+	return tmTxs.Hash()
+}
+
+// tmValidatorSet converts a complete validator-set returned by the tmservice to a tendermint validator-set,
+// keeping the upstream order, which is the one hashed into Header.ValidatorsHash
+func (c *Chain) tmValidatorSet(vs []*tmservice.Validator) (*tmtypes.ValidatorSet, error) {
+	set := &tmtypes.ValidatorSet{Validators: make([]*tmtypes.Validator, 0, len(vs))}
+	for _, v := range vs {
+		pk, err := c.decodePubKey(v.PubKey)
+		if err != nil {
+			return nil, err
+		}
+		tmPk, err := cryptocodec.ToTmPubKeyInterface(pk)
+		if err != nil {
+			return nil, err
+		}
+		val := tmtypes.NewValidator(tmPk, v.VotingPower)
+		val.ProposerPriority = v.ProposerPriority
+		set.Validators = append(set.Validators, val)
+	}
+	if len(set.Validators) > 0 {
+		// the proposer is the validator with the highest priority, as tendermint picks it
+		set.Proposer = set.GetProposer()
+	}
+	return set, nil
+}
+
+// verifyCommit checks that commit, the LastCommit of the block after header, carries valid signatures of the
+// recomputed header hash from more than 2/3 of the voting power of set, the validator-set at the header height
+func verifyCommit(header tmproto.Header, set *tmtypes.ValidatorSet, commit *tmproto.Commit) *types.VerifyCommitResponse {
+	ans := &types.VerifyCommitResponse{Height: header.Height, TotalVotingPower: set.TotalVotingPower()}
+	h, err := tmtypes.HeaderFromProto(&header)
+	if err != nil {
+		ans.Error = fmt.Sprintf("invalid header: %v", err)
+		return ans
+	}
+	hash := h.Hash()
+	ans.BlockHash = hash.String()
+	c, err := tmtypes.CommitFromProto(commit)
+	if err != nil {
+		ans.Error = fmt.Sprintf("invalid commit: %v", err)
+		return ans
+	}
+	for i, sig := range c.Signatures {
+		if sig.ForBlock() && i < set.Size() {
+			ans.SignedVotingPower += set.Validators[i].VotingPower
+		}
+	}
+	// the part set header cannot be recomputed from the block alone, the header hash is what ties the commit to it
+	blockID := tmtypes.BlockID{Hash: hash, PartSetHeader: c.BlockID.PartSetHeader}
+	if err := set.VerifyCommit(h.ChainID, blockID, h.Height, c); err != nil {
+		ans.Error = err.Error()
+		return ans
+	}
+	ans.Verified = true
+	return ans
+}
@@ -0,0 +1,287 @@
+// This file contains tests for the block hash verification behind GetBlockByHeight and the commit signature
+// verification behind VerifyCommit.
+//
+// The blocks are built locally with the tendermint types and every LastCommit is signed by the validator keys,
+// so every hash and signature in them is genuine.
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	tmed25519 "github.com/tendermint/tendermint/crypto/ed25519"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// TEST_CHAIN_ID is the chain id of the blocks built by newTestChain
+const TEST_CHAIN_ID = "osmosis-1"
+
+// testChain is a chain of blocks from height 1 with genuine hashes and signed commits
+type testChain struct {
+	// set is the validator-set of the first block, sets the one of every block
+	set    *tmtypes.ValidatorSet
+	sets   map[int64]*tmtypes.ValidatorSet
+	blocks map[int64]*tmproto.Block
+	ids    map[int64]*tmproto.BlockID
+}
+
+// newTestChain builds n blocks of one tx each, made by validators validators with voting power 1..validators.
+// Every validator signs the commit of each block, carried as the LastCommit of the next one.
+func newTestChain(t *testing.T, validators, n int) *testChain {
+	return newRotatingTestChain(t, validators, n, 0)
+}
+
+// newRotatingTestChain is newTestChain with the validator-set replaced by new validators every rotate blocks,
+// or never when rotate is 0
+func newRotatingTestChain(t *testing.T, validators, n, rotate int) *testChain {
+	c := &testChain{
+		sets:   make(map[int64]*tmtypes.ValidatorSet),
+		blocks: make(map[int64]*tmproto.Block),
+		ids:    make(map[int64]*tmproto.BlockID),
+	}
+	epoch := func(h int64) int {
+		if rotate == 0 {
+			return 0
+		}
+		return int(h-1) / rotate
+	}
+	// the validator-sets and signers of each epoch, including the next set of the last block
+	epochSets := make(map[int]*tmtypes.ValidatorSet)
+	signers := make(map[int][]tmtypes.PrivValidator)
+	for h := int64(1); h <= int64(n)+1; h++ {
+		e := epoch(h)
+		if _, ok := epochSets[e]; !ok {
+			epochSets[e], signers[e] = testSigners(e*validators, validators)
+		}
+		c.sets[h] = epochSets[e]
+	}
+	c.set = c.sets[1]
+	var lastID tmtypes.BlockID
+	lastCommit := &tmtypes.Commit{}
+	for h := int64(1); h <= int64(n); h++ {
+		if h > 1 {
+			voteSet := tmtypes.NewVoteSet(TEST_CHAIN_ID, h-1, 0, tmproto.PrecommitType, c.sets[h-1])
+			commit, err := tmtypes.MakeCommit(lastID, h-1, 0, voteSet, signers[epoch(h-1)], time.Unix(1600000000+h, 0).UTC())
+			if err != nil {
+				t.Fatal(err)
+			}
+			lastCommit = commit
+		}
+		block := tmtypes.MakeBlock(h, []tmtypes.Tx{tmtypes.Tx(fmt.Sprintf("tx-%d", h))}, lastCommit, nil)
+		block.ChainID = TEST_CHAIN_ID
+		block.Time = time.Unix(1600000000+h, 0).UTC()
+		block.LastBlockID = lastID
+		block.ValidatorsHash = c.sets[h].Hash()
+		block.NextValidatorsHash = c.sets[h+1].Hash()
+		block.ProposerAddress = c.sets[h].GetProposer().Address
+		parts := block.MakePartSet(tmtypes.BlockPartSizeBytes)
+		lastID = tmtypes.BlockID{Hash: block.Hash(), PartSetHeader: parts.Header()}
+		pb, err := block.ToProto()
+		if err != nil {
+			t.Fatal(err)
+		}
+		id := lastID.ToProto()
+		c.blocks[h], c.ids[h] = pb, &id
+	}
+	return c
+}
+
+// testSigners returns the validator-set of the test validators offset+1..offset+n, with voting power 1..n,
+// and their signers in validator-set order
+func testSigners(offset, n int) (*tmtypes.ValidatorSet, []tmtypes.PrivValidator) {
+	vals := make([]*tmtypes.Validator, 0, n)
+	byAddress := make(map[string]tmed25519.PrivKey)
+	for i := 1; i <= n; i++ {
+		priv := tmed25519.GenPrivKeyFromSecret([]byte(fmt.Sprintf("validator-%d", offset+i)))
+		byAddress[priv.PubKey().Address().String()] = priv
+		vals = append(vals, tmtypes.NewValidator(priv.PubKey(), int64(i)))
+	}
+	set := tmtypes.NewValidatorSet(vals)
+	pvs := make([]tmtypes.PrivValidator, 0, n)
+	for _, v := range set.Validators {
+		pvs = append(pvs, tmtypes.NewMockPVWithParams(byAddress[v.Address.String()], false, false))
+	}
+	return set, pvs
+}
+
+// tmserviceValidators returns a validator-set the way the tmservice serves it
+func tmserviceValidators(set *tmtypes.ValidatorSet) []*tmservice.Validator {
+	vs := make([]*tmservice.Validator, 0, len(set.Validators))
+	for _, v := range set.Validators {
+		pkAny, err := codectypes.NewAnyWithValue(&ed25519.PubKey{Key: v.PubKey.Bytes()})
+		if err != nil {
+			panic(err)
+		}
+		vs = append(vs, &tmservice.Validator{
+			Address:          sdk.ConsAddress(v.Address).String(),
+			PubKey:           pkAny,
+			VotingPower:      v.VotingPower,
+			ProposerPriority: v.ProposerPriority,
+		})
+	}
+	return vs
+}
+
+// service returns a fake tmservice serving the blocks and validator-sets of the chain
+func (c *testChain) service() *fakeValidatorService {
+	sets := make(map[int64][]*tmservice.Validator)
+	for h := range c.blocks {
+		sets[h] = tmserviceValidators(c.sets[h])
+	}
+	return &fakeValidatorService{latest: int64(len(c.blocks)), sets: sets, blocks: c.blocks, ids: c.ids}
+}
+
+// TestVerifyBlock tests that the hashes of a genuine block verify
+func TestVerifyBlock(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	chain := newTestChain(t, 4, 3)
+	for h := int64(1); h <= 3; h++ {
+		ans, err := verifyBlock(context.Background(), chain.service(), chain.ids[h], chain.blocks[h])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ans.Verified || len(ans.Errors) > 0 {
+			t.Errorf("block %d did not verify: %v", h, ans.Errors)
+		}
+	}
+}
+
+// TestVerifyBlockTampered tests that each recomputed hash catches the part of the block it covers
+func TestVerifyBlockTampered(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	// txs replaced: the data hash no longer matches the header
+	chain := newTestChain(t, 4, 2)
+	chain.blocks[2].Data.Txs = [][]byte{[]byte("forged")}
+	ans, err := verifyBlock(context.Background(), chain.service(), chain.ids[2], chain.blocks[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ans.Verified || ans.DataHashOk || !ans.HeaderHashOk {
+		t.Errorf("unexpected verification of forged txs %v", ans)
+	}
+
+	// header changed: the header hash no longer matches the block id
+	chain = newTestChain(t, 4, 2)
+	chain.blocks[2].Header.AppHash = make([]byte, 32)
+	ans, err = verifyBlock(context.Background(), chain.service(), chain.ids[2], chain.blocks[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ans.Verified || ans.HeaderHashOk || !ans.DataHashOk {
+		t.Errorf("unexpected verification of a forged header %v", ans)
+	}
+
+	// validator-set served by the upstream differs from the one in the header
+	chain = newTestChain(t, 4, 2)
+	client := chain.service()
+	client.sets[2] = client.sets[2][1:]
+	ans, err = verifyBlock(context.Background(), client, chain.ids[2], chain.blocks[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ans.Verified || ans.ValidatorsHashOk || !ans.HeaderHashOk {
+		t.Errorf("unexpected verification of a forged validator-set %v", ans)
+	}
+
+	// previous block replaced by one of another chain: the header link is broken
+	chain, other := newTestChain(t, 4, 2), newTestChain(t, 3, 2)
+	client = chain.service()
+	client.blocks[1] = other.blocks[1]
+	ans, err = verifyBlock(context.Background(), client, chain.ids[2], chain.blocks[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ans.Verified || ans.LastBlockIdOk || !ans.HeaderHashOk {
+		t.Errorf("unexpected verification of a broken header link %v", ans)
+	}
+}
+
+// TestVerifyCommit tests that the signed commit of a block verifies against the validator-set
+func TestVerifyCommit(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	chain := newTestChain(t, 4, 3)
+	ans := verifyCommit(chain.blocks[2].Header, chain.set, chain.blocks[3].LastCommit)
+	if !ans.Verified || ans.Error != "" {
+		t.Fatalf("commit did not verify: %s", ans.Error)
+	}
+	if ans.SignedVotingPower != 10 || ans.TotalVotingPower != 10 || ans.BlockHash != fmt.Sprintf("%X", chain.ids[2].Hash) {
+		t.Errorf("unexpected verification %v", ans)
+	}
+
+	// the validator-set converted from the tmservice one verifies the same commit
+	set, err := newValidatorSets(chain.service()).tmAt(context.Background(), chain.blocks[2].Header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ans := verifyCommit(chain.blocks[2].Header, set, chain.blocks[3].LastCommit); !ans.Verified {
+		t.Errorf("commit did not verify with the tmservice validator-set: %s", ans.Error)
+	}
+}
+
+// TestVerifyCommitInvalid tests that forged signatures, missing voting power and a commit for another block are rejected
+func TestVerifyCommitInvalid(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	// a signature is altered
+	chain := newTestChain(t, 4, 3)
+	chain.blocks[3].LastCommit.Signatures[1].Signature[0] ^= 0xff
+	if ans := verifyCommit(chain.blocks[2].Header, chain.set, chain.blocks[3].LastCommit); ans.Verified {
+		t.Error("expected a forged signature to fail")
+	}
+
+	// the validators with 4 and 3 of the 10 voting power are absent, leaving 3/10
+	chain = newTestChain(t, 4, 3)
+	for i := 0; i < 2; i++ {
+		chain.blocks[3].LastCommit.Signatures[i] = tmproto.CommitSig{BlockIdFlag: tmproto.BlockIDFlagAbsent}
+	}
+	ans := verifyCommit(chain.blocks[2].Header, chain.set, chain.blocks[3].LastCommit)
+	if ans.Verified || ans.SignedVotingPower != 3 {
+		t.Errorf("expected a commit without 2/3 of the voting power to fail, got %v", ans)
+	}
+
+	// the header is altered, so the commit signs another block
+	chain = newTestChain(t, 4, 3)
+	chain.blocks[2].Header.AppHash = make([]byte, 32)
+	if ans := verifyCommit(chain.blocks[2].Header, chain.set, chain.blocks[3].LastCommit); ans.Verified {
+		t.Error("expected a commit for another block to fail")
+	}
+}
+
+// TestUptimeReportVerifyCommits tests that the uptime report checks every commit it reads when asked to
+func TestUptimeReportVerifyCommits(t *testing.T) {
+	// initialize the global Ccontext object
+	InitCcontext()
+
+	chain := newTestChain(t, 4, 5)
+	report, err := uptimeReport(context.Background(), chain.service(), 1, 4, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for address, uptime := range report {
+		if uptime.Blocks != 4 || uptime.Signed != 4 {
+			t.Errorf("unexpected uptime of %s %v", address, uptime)
+		}
+	}
+
+	chain.blocks[4].LastCommit.Signatures[0].Signature[0] ^= 0xff
+	if _, err := uptimeReport(context.Background(), chain.service(), 1, 4, false); err != nil {
+		t.Errorf("unexpected error without verification: %v", err)
+	}
+	if _, err := uptimeReport(context.Background(), chain.service(), 1, 4, true); err == nil {
+		t.Error("expected a forged commit to fail the verified report")
+	}
+}
@@ -0,0 +1,221 @@
+// This package contains a client for the Tendermint JSON-RPC of a node.
+//
+// Requests are POSTed as JSON-RPC 2.0 calls with named params encoded like the Tendermint RPC expects them, and
+// results are decoded into the Tendermint core types. Every Client shares one HTTP transport, so calls to the same
+// node reuse its connections. WSClient subscribes to the events of a node on its websocket.
+package tmrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+// MAX_IDLE_CONNS_PER_HOST is the number of idle connections kept open to every node
+const MAX_IDLE_CONNS_PER_HOST = 16
+
+// IDLE_CONN_TIMEOUT is how long an idle connection to a node is kept open
+const IDLE_CONN_TIMEOUT = 90 * time.Second
+
+// HTTPClient is the HTTP client shared by every Client
+var HTTPClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		MaxIdleConnsPerHost: MAX_IDLE_CONNS_PER_HOST,
+		IdleConnTimeout:     IDLE_CONN_TIMEOUT,
+	},
+}
+
+// Client calls the Tendermint JSON-RPC of one node
+type Client struct {
+	remote string
+	http   *http.Client
+	nextID int64
+}
+
+// Request is a JSON-RPC 2.0 request
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int64           `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// Response is a JSON-RPC 2.0 response, Result is left encoded
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int64           `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// RPCError is the error member of a JSON-RPC response
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data,omitempty"`
+}
+
+// Error implements error
+func (e *RPCError) Error() string {
+	if e.Data != "" {
+		return fmt.Sprintf("RPC error %d - %s: %s", e.Code, e.Message, e.Data)
+	}
+	return fmt.Sprintf("RPC error %d - %s", e.Code, e.Message)
+}
+
+// New returns a client of the node at remote, e.g. https://rpc.osmosis.zone:443 or tcp://localhost:26657
+func New(remote string) *Client {
+	if strings.HasPrefix(remote, "tcp://") {
+		remote = "http://" + strings.TrimPrefix(remote, "tcp://")
+	}
+	return &Client{remote: strings.TrimSuffix(remote, "/"), http: HTTPClient}
+}
+
+// Remote returns the address of the node
+func (c *Client) Remote() string {
+	return c.remote
+}
+
+// Do calls method with the named params and returns the response, failing on a transport error or a response
+// that is not JSON-RPC. A JSON-RPC error is returned in the response, not as the error.
+func (c *Client) Do(ctx context.Context, method string, params map[string]interface{}) (*Response, error) {
+	encoded := make(map[string]json.RawMessage, len(params))
+	for name, value := range params {
+		// tendermint decodes params with its own JSON encoding, which writes 64-bit integers as strings
+		bz, err := tmjson.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode param %s: %w", name, err)
+		}
+		encoded[name] = bz
+	}
+	paramsBytes, err := json.Marshal(encoded)
+	if err != nil {
+		return nil, err
+	}
+	id := atomic.AddInt64(&c.nextID, 1)
+	body, err := json.Marshal(&Request{JSONRPC: "2.0", ID: id, Method: method, Params: paramsBytes})
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.remote, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpResp, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	respBytes, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	// tendermint answers JSON-RPC errors with a 500 status, so the body is read whatever the status
+	var resp Response
+	if err := json.Unmarshal(respBytes, &resp); err != nil || (resp.Result == nil && resp.Error == nil) {
+		return nil, fmt.Errorf("%s %s: unexpected response %s", method, httpResp.Status, truncate(respBytes))
+	}
+	if resp.ID != id {
+		return nil, fmt.Errorf("%s: response id %d does not match request id %d", method, resp.ID, id)
+	}
+	return &resp, nil
+}
+
+// Call calls method with the named params and decodes its result into result
+func (c *Client) Call(ctx context.Context, method string, params map[string]interface{}, result interface{}) error {
+	resp, err := c.Do(ctx, method, params)
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if err := tmjson.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("%s: failed to decode result: %w", method, err)
+	}
+	return nil
+}
+
+// ABCIInfo returns the abci_info of the node
+func (c *Client) ABCIInfo(ctx context.Context) (*coretypes.ResultABCIInfo, error) {
+	result := new(coretypes.ResultABCIInfo)
+	if err := c.Call(ctx, "abci_info", nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Status returns the status of the node
+func (c *Client) Status(ctx context.Context) (*coretypes.ResultStatus, error) {
+	result := new(coretypes.ResultStatus)
+	if err := c.Call(ctx, "status", nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// BlockResults returns the ABCI results of the block at height, the latest one when height is nil
+func (c *Client) BlockResults(ctx context.Context, height *int64) (*coretypes.ResultBlockResults, error) {
+	params := make(map[string]interface{})
+	if height != nil {
+		params["height"] = height
+	}
+	result := new(coretypes.ResultBlockResults)
+	if err := c.Call(ctx, "block_results", params, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Commit returns the signed header of the block at height, the latest one when height is nil
+func (c *Client) Commit(ctx context.Context, height *int64) (*coretypes.ResultCommit, error) {
+	params := make(map[string]interface{})
+	if height != nil {
+		params["height"] = height
+	}
+	result := new(coretypes.ResultCommit)
+	if err := c.Call(ctx, "commit", params, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TxSearch returns a page of the txs matching a tendermint event query, orderBy being asc, desc or empty
+func (c *Client) TxSearch(ctx context.Context, query string, prove bool, page, perPage *int, orderBy string) (*coretypes.ResultTxSearch, error) {
+	params := map[string]interface{}{
+		"query":    query,
+		"prove":    prove,
+		"order_by": orderBy,
+	}
+	if page != nil {
+		params["page"] = page
+	}
+	if perPage != nil {
+		params["per_page"] = perPage
+	}
+	result := new(coretypes.ResultTxSearch)
+	if err := c.Call(ctx, "tx_search", params, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// truncate shortens a response body quoted in an error
+func truncate(bz []byte) string {
+	const max = 200
+	if len(bz) > max {
+		return string(bz[:max]) + "..."
+	}
+	return string(bz)
+}
@@ -0,0 +1,131 @@
+// This file contains tests for the Tendermint JSON-RPC client against a fake node.
+package tmrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeNode serves handle as the JSON-RPC of a node, answering with the request id
+func fakeNode(t *testing.T, handle func(req *Request) (result string, rpcErr *RPCError)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid request: %v", err)
+			return
+		}
+		result, rpcErr := handle(&req)
+		resp := &Response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}
+		if rpcErr == nil {
+			resp.Result = json.RawMessage(result)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Error(err)
+		}
+	}))
+}
+
+// TestABCIInfo tests that a result is decoded into the tendermint core type
+func TestABCIInfo(t *testing.T) {
+	node := fakeNode(t, func(req *Request) (string, *RPCError) {
+		if req.Method != "abci_info" {
+			t.Errorf("expected abci_info, got %s", req.Method)
+		}
+		return `{"response":{"data":"OsmosisApp","version":"12.3.0","app_version":"1","last_block_height":"8700000"}}`, nil
+	})
+	defer node.Close()
+
+	info, err := New(node.URL).ABCIInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Response.Version != "12.3.0" || info.Response.LastBlockHeight != 8700000 {
+		t.Errorf("unexpected abci info %+v", info.Response)
+	}
+}
+
+// TestParams tests that params are encoded the way tendermint decodes them, 64-bit integers as strings
+func TestParams(t *testing.T) {
+	var params map[string]json.RawMessage
+	node := fakeNode(t, func(req *Request) (string, *RPCError) {
+		params = nil
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			t.Error(err)
+		}
+		if req.Method == "tx_search" {
+			return `{"txs":[],"total_count":"0"}`, nil
+		}
+		return `{"height":"5","txs_results":null,"begin_block_events":null,"end_block_events":null,"validator_updates":null,"consensus_param_updates":null}`, nil
+	})
+	defer node.Close()
+
+	height := int64(5)
+	results, err := New(node.URL).BlockResults(context.Background(), &height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results.Height != 5 || string(params["height"]) != `"5"` {
+		t.Errorf("expected height 5 sent as a string, got %d and %s", results.Height, params["height"])
+	}
+
+	page, perPage := 2, 10
+	if _, err := New(node.URL).TxSearch(context.Background(), "tx.hash='AB'", false, &page, &perPage, "desc"); err != nil {
+		t.Fatal(err)
+	}
+	if string(params["query"]) != `"tx.hash='AB'"` || string(params["page"]) != `"2"` || string(params["order_by"]) != `"desc"` {
+		t.Errorf("unexpected tx_search params %s", params)
+	}
+	if _, ok := params["height"]; ok {
+		t.Error("unexpected height param in tx_search")
+	}
+}
+
+// TestRPCError tests that a JSON-RPC error is returned as an RPCError
+func TestRPCError(t *testing.T) {
+	node := fakeNode(t, func(req *Request) (string, *RPCError) {
+		return "", &RPCError{Code: -32603, Message: "Internal error", Data: "height 100 must be less than or equal to the current blockchain height 10"}
+	})
+	defer node.Close()
+
+	height := int64(100)
+	_, err := New(node.URL).Commit(context.Background(), &height)
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != -32603 {
+		t.Fatalf("expected an RPC error, got %v", err)
+	}
+}
+
+// TestNotJSONRPC tests that a response that is not JSON-RPC fails with the HTTP status
+func TestNotJSONRPC(t *testing.T) {
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer node.Close()
+
+	if _, err := New(node.URL).Status(context.Background()); err == nil {
+		t.Error("expected an error for a non JSON-RPC response")
+	}
+}
+
+// TestCancel tests that a call returns when its context is cancelled
+func TestCancel(t *testing.T) {
+	release := make(chan struct{})
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer node.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := New(node.URL).Status(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+}
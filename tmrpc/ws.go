@@ -0,0 +1,210 @@
+package tmrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// WS_ENDPOINT is the path of the tendermint websocket
+const WS_ENDPOINT = "/websocket"
+
+// WS_PING_PERIOD is how often a ping keeps the websocket alive
+const WS_PING_PERIOD = 20 * time.Second
+
+// WS_READ_TIMEOUT is how long the websocket may stay silent, pongs included, before it is considered lost
+const WS_READ_TIMEOUT = 3 * WS_PING_PERIOD
+
+// WS_WRITE_TIMEOUT bounds the writes of requests and pings
+const WS_WRITE_TIMEOUT = 10 * time.Second
+
+// WS_MIN_BACKOFF and WS_MAX_BACKOFF bound the wait before a reconnection, which doubles after every failed attempt
+const (
+	WS_MIN_BACKOFF = time.Second
+	WS_MAX_BACKOFF = 30 * time.Second
+)
+
+// Event is an event pushed for a subscription
+type Event struct {
+	Query  string
+	Data   tmtypes.TMEventData
+	Events map[string][]string
+}
+
+// WSClient subscribes to events with the tendermint websocket subscribe API. When the connection is lost it
+// reconnects and subscribes again to every query, so the events of the blocks produced meanwhile are missed.
+type WSClient struct {
+	remote  string
+	queries []string
+	dialer  *websocket.Dialer
+	// OnConnect, when set, is called with true once every query is subscribed after a connection,
+	// and with false when that connection is lost
+	OnConnect func(connected bool)
+	// OnError, when set, is called with the error ending a connection attempt or a connection
+	OnError    func(err error)
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mtx  sync.Mutex
+	conn *websocket.Conn
+}
+
+// NewWSClient returns a client subscribing to the queries on the websocket of the node at remote
+func NewWSClient(remote string, queries ...string) *WSClient {
+	switch {
+	case strings.HasPrefix(remote, "https://"):
+		remote = "wss://" + strings.TrimPrefix(remote, "https://")
+	case strings.HasPrefix(remote, "http://"):
+		remote = "ws://" + strings.TrimPrefix(remote, "http://")
+	case strings.HasPrefix(remote, "tcp://"):
+		remote = "ws://" + strings.TrimPrefix(remote, "tcp://")
+	}
+	return &WSClient{
+		remote:     strings.TrimSuffix(remote, "/") + WS_ENDPOINT,
+		queries:    queries,
+		dialer:     websocket.DefaultDialer,
+		minBackoff: WS_MIN_BACKOFF,
+		maxBackoff: WS_MAX_BACKOFF,
+	}
+}
+
+// Reconnect closes the current connection, Run connects and subscribes again after the shortest backoff
+func (c *WSClient) Reconnect() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
+
+// setConn records the current connection, nil when there is none
+func (c *WSClient) setConn(conn *websocket.Conn) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.conn = conn
+}
+
+// Run passes every event to handle until ctx is done, reconnecting whenever the connection fails.
+// Events are handled one at a time in the order the node sends them.
+func (c *WSClient) Run(ctx context.Context, handle func(Event)) error {
+	backoff := c.minBackoff
+	for {
+		subscribed, err := c.session(ctx, handle)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if c.OnError != nil {
+			c.OnError(err)
+		}
+		if subscribed {
+			backoff = c.minBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > c.maxBackoff {
+			backoff = c.maxBackoff
+		}
+	}
+}
+
+// session connects, subscribes to every query and handles events until the connection fails.
+// It reports whether the subscriptions were made.
+func (c *WSClient) session(ctx context.Context, handle func(Event)) (bool, error) {
+	conn, _, err := c.dialer.DialContext(ctx, c.remote, nil)
+	if err != nil {
+		return false, err
+	}
+	c.setConn(conn)
+	defer c.setConn(nil)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		// closing the connection unblocks the read loop when ctx is done
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(WS_READ_TIMEOUT))
+	})
+	pending := make(map[int64]string, len(c.queries))
+	for i, query := range c.queries {
+		params, err := json.Marshal(map[string]string{"query": query})
+		if err != nil {
+			return false, err
+		}
+		req := &Request{JSONRPC: "2.0", ID: int64(i + 1), Method: "subscribe", Params: params}
+		conn.SetWriteDeadline(time.Now().Add(WS_WRITE_TIMEOUT))
+		if err := conn.WriteJSON(req); err != nil {
+			return false, err
+		}
+		pending[req.ID] = query
+	}
+	subscribed := len(pending) == 0
+	if subscribed && c.OnConnect != nil {
+		c.OnConnect(true)
+	}
+	defer func() {
+		if subscribed && c.OnConnect != nil {
+			c.OnConnect(false)
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(WS_PING_PERIOD)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(WS_WRITE_TIMEOUT)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(WS_READ_TIMEOUT))
+		var resp Response
+		if err := conn.ReadJSON(&resp); err != nil {
+			return subscribed, err
+		}
+		if resp.Error != nil {
+			return subscribed, fmt.Errorf("subscription %d: %w", resp.ID, resp.Error)
+		}
+		var result coretypes.ResultEvent
+		if err := tmjson.Unmarshal(resp.Result, &result); err != nil {
+			return subscribed, fmt.Errorf("failed to decode event: %w", err)
+		}
+		if result.Query == "" {
+			// the empty result acknowledging a subscribe request
+			if _, ok := pending[resp.ID]; ok {
+				delete(pending, resp.ID)
+				if len(pending) == 0 && !subscribed {
+					subscribed = true
+					if c.OnConnect != nil {
+						c.OnConnect(true)
+					}
+				}
+			}
+			continue
+		}
+		handle(Event{Query: result.Query, Data: result.Data, Events: result.Events})
+	}
+}
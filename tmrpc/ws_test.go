@@ -0,0 +1,126 @@
+// This file contains tests for the websocket event subscriptions against a fake node.
+package tmrpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// fakeWebsocket acknowledges every subscribe request, then pushes one NewBlock event of the next height and
+// closes the connection, so every connection of a client is a new session
+func fakeWebsocket(t *testing.T) (*httptest.Server, func() []string) {
+	var (
+		mtx        sync.Mutex
+		subscribes []string
+		height     int64
+	)
+	upgrader := websocket.Upgrader{}
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != WS_ENDPOINT {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		var req Request
+		if err := conn.ReadJSON(&req); err != nil {
+			t.Error(err)
+			return
+		}
+		var params map[string]string
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			t.Error(err)
+		}
+		mtx.Lock()
+		subscribes = append(subscribes, params["query"])
+		height++
+		h := height
+		mtx.Unlock()
+		if err := conn.WriteJSON(&Response{JSONRPC: "2.0", ID: req.ID, Result: json.RawMessage(`{}`)}); err != nil {
+			t.Error(err)
+		}
+		block := &tmtypes.Block{Header: tmtypes.Header{ChainID: "osmosis-1", Height: h}}
+		result, err := tmjson.Marshal(&coretypes.ResultEvent{Query: params["query"], Data: tmtypes.EventDataNewBlock{Block: block}})
+		if err != nil {
+			t.Error(err)
+		}
+		if err := conn.WriteJSON(&Response{JSONRPC: "2.0", ID: req.ID, Result: result}); err != nil {
+			t.Error(err)
+		}
+	}))
+	return node, func() []string {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return append([]string(nil), subscribes...)
+	}
+}
+
+// TestWSClientReconnect tests that events are decoded and that a lost connection is subscribed again
+func TestWSClientReconnect(t *testing.T) {
+	node, subscribes := fakeWebsocket(t)
+	defer node.Close()
+
+	query := tmtypes.EventQueryNewBlock.String()
+	c := NewWSClient(node.URL, query)
+	c.minBackoff, c.maxBackoff = time.Millisecond, time.Millisecond
+	var connects, disconnects int
+	c.OnConnect = func(connected bool) {
+		if connected {
+			connects++
+		} else {
+			disconnects++
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var heights []int64
+	err := c.Run(ctx, func(e Event) {
+		newBlock, ok := e.Data.(tmtypes.EventDataNewBlock)
+		if !ok || e.Query != query {
+			t.Fatalf("unexpected event %+v", e)
+		}
+		if heights = append(heights, newBlock.Block.Height); len(heights) == 3 {
+			cancel()
+		}
+	})
+	if err != context.Canceled {
+		t.Fatalf("expected the run to be cancelled, got %v", err)
+	}
+	if len(heights) != 3 || heights[0] != 1 || heights[2] != 3 {
+		t.Errorf("expected the heights 1 to 3, got %v", heights)
+	}
+	if got := subscribes(); len(got) < 3 || got[2] != query {
+		t.Errorf("expected a subscription per connection, got %v", got)
+	}
+	if connects < 3 || disconnects != connects {
+		t.Errorf("expected a connect and disconnect per connection, got %d and %d", connects, disconnects)
+	}
+}
+
+// TestNewWSClient tests that the websocket address is derived from the RPC address
+func TestNewWSClient(t *testing.T) {
+	remotes := map[string]string{
+		"https://rpc.osmosis.zone:443": "wss://rpc.osmosis.zone:443/websocket",
+		"http://localhost:26657/":      "ws://localhost:26657/websocket",
+		"tcp://localhost:26657":        "ws://localhost:26657/websocket",
+	}
+	for remote, expected := range remotes {
+		if c := NewWSClient(remote); c.remote != expected {
+			t.Errorf("NewWSClient(%q) = %s, expected %s", remote, c.remote, expected)
+		}
+	}
+}